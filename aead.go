@@ -0,0 +1,311 @@
+package binutil
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// AEAD ciphers that can be used to seal and open payloads.
+const (
+	AESGCMCipher AEADCipher = iota
+	ChaCha20Poly1305Cipher
+)
+
+// Nonce layouts determine where the nonce is stored relative to the ciphertext. Prefix
+// and suffix layouts store a random nonce with the ciphertext; the separate layout
+// requires the nonce to be supplied with the iv option and only the ciphertext is
+// encoded. Every value sealed with the separate layout uses the same nonce, so a step
+// with the separate layout must only seal a single value with a given key (e.g. not
+// every line or row of a file); it is intended for opening values whose nonce is stored
+// elsewhere.
+const (
+	NoncePrefix NonceLayout = iota
+	NonceSuffix
+	NonceSeparate
+)
+
+func init() {
+	RegisterOptionsDecoder(AESGCMDecoder, func(opts Options) (Decoder, error) { return NewAEADFromOptions(AESGCMCipher, opts) }, "aes-gcm")
+	RegisterOptionsDecoder(ChaCha20Poly1305Decoder, func(opts Options) (Decoder, error) { return NewAEADFromOptions(ChaCha20Poly1305Cipher, opts) }, "chacha20-poly1305", "chacha20")
+
+	aeadOptions := []string{"key", "nonce", "iv", "aad"}
+	DescribeDecoder(AESGCMDecoder, Info{Description: "AES-GCM authenticated encryption of the plaintext string", Category: CryptoCategory, Capabilities: Bidirectional | Lossy, Options: aeadOptions})
+	DescribeDecoder(ChaCha20Poly1305Decoder, Info{Description: "ChaCha20-Poly1305 authenticated encryption of the plaintext string", Category: CryptoCategory, Capabilities: Bidirectional | Lossy, Options: aeadOptions})
+}
+
+const (
+	AESGCMDecoder           = "aesgcm"
+	ChaCha20Poly1305Decoder = "chacha20poly1305"
+)
+
+// NewAEAD returns an AEAD step for the specified cipher and key using the default
+// nonce prefix layout and no associated data.
+func NewAEAD(alg AEADCipher, key []byte) *AEAD {
+	return &AEAD{Cipher: alg, Key: key}
+}
+
+// NewAEADFromOptions creates an AEAD step from the step specification options. The key
+// option is required and should reference a file or environment variable (e.g.
+// key=env:DATA_KEY or key=file:data.key) containing the raw key, or the key prefixed
+// with hex: or b64: if it is encoded; the key is never decoded otherwise and must be a
+// valid size for the cipher. The nonce option selects the layout (prefix, suffix, or
+// separate), the iv option supplies the nonce for the separate layout (and is rejected
+// by the other layouts), and the aad option supplies the associated data; both iv and
+// aad accept the references described by Options.Bytes.
+func NewAEADFromOptions(alg AEADCipher, opts Options) (_ *AEAD, err error) {
+	a := &AEAD{Cipher: alg}
+	if a.Key, err = keyFromOptions(opts); err != nil {
+		return nil, err
+	}
+
+	if a.Key == nil {
+		return nil, ErrNoKey
+	}
+
+	if !a.validKey() {
+		return nil, fmt.Errorf("%w: %s requires a %s key, got %d bytes", ErrInvalidKey, alg, alg.keySizes(), len(a.Key))
+	}
+
+	switch strings.ToLower(opts.Get("nonce", "prefix")) {
+	case "prefix":
+		a.Nonce = NoncePrefix
+	case "suffix":
+		a.Nonce = NonceSuffix
+	case "separate":
+		a.Nonce = NonceSeparate
+	default:
+		return nil, ErrUnknownNonceLayout
+	}
+
+	if a.IV, err = opts.Bytes("iv"); err != nil {
+		return nil, err
+	}
+
+	// The prefix and suffix layouts store the nonce with the ciphertext so the iv option
+	// would only replace the random nonce of every sealed value with a fixed one.
+	if a.IV != nil && a.Nonce != NonceSeparate {
+		return nil, ErrFixedNonce
+	}
+
+	if a.AssociatedData, err = opts.Bytes("aad"); err != nil {
+		return nil, err
+	}
+
+	// Ensure the key is valid for the cipher before any data is processed.
+	if _, err = a.aead(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// AEAD implements the encoder and decoder interface for authenticated encryption. The
+// binary representation is the sealed ciphertext (including the nonce unless the nonce
+// layout is separate) and the string representation is the plaintext. Decoding binary
+// data opens the ciphertext and encoding binary data seals the plaintext with a new
+// random nonce (or the iv if one was supplied), so a Base64 step before an AEAD step
+// decrypts and an AEAD step before a Base64 step encrypts.
+type AEAD struct {
+	Cipher         AEADCipher
+	Key            []byte
+	Nonce          NonceLayout
+	IV             []byte
+	AssociatedData []byte
+	data           []byte
+}
+
+var (
+	_ Encoder = &AEAD{}
+	_ Decoder = &AEAD{}
+)
+
+// DecodeBinary opens the sealed payload and returns an encoder wrapping the plaintext.
+func (a AEAD) DecodeBinary(in []byte) (_ Encoder, err error) {
+	var aead cipher.AEAD
+	if aead, err = a.aead(); err != nil {
+		return nil, err
+	}
+
+	var nonce, ciphertext []byte
+	size := aead.NonceSize()
+
+	switch a.Nonce {
+	case NoncePrefix:
+		if len(in) < size+aead.Overhead() {
+			return nil, ErrCiphertextTooShort
+		}
+		nonce, ciphertext = in[:size], in[size:]
+	case NonceSuffix:
+		if len(in) < size+aead.Overhead() {
+			return nil, ErrCiphertextTooShort
+		}
+		nonce, ciphertext = in[len(in)-size:], in[:len(in)-size]
+	case NonceSeparate:
+		if len(a.IV) != size {
+			return nil, ErrInvalidNonce
+		}
+		nonce, ciphertext = a.IV, in
+	default:
+		return nil, ErrUnknownNonceLayout
+	}
+
+	out := a.clone()
+	if out.data, err = aead.Open(make([]byte, 0, len(ciphertext)), nonce, ciphertext, a.AssociatedData); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DecodeString returns an encoder wrapping the plaintext string, ready to be sealed.
+func (a AEAD) DecodeString(in string) (Encoder, error) {
+	out := a.clone()
+	out.data = []byte(in)
+	return out, nil
+}
+
+// EncodeBinary seals the wrapped plaintext, storing the nonce according to the layout.
+func (a AEAD) EncodeBinary() (_ []byte, err error) {
+	if a.data == nil {
+		return nil, ErrNoData
+	}
+
+	var aead cipher.AEAD
+	if aead, err = a.aead(); err != nil {
+		return nil, err
+	}
+
+	nonce := a.IV
+	if nonce == nil {
+		if a.Nonce == NonceSeparate {
+			return nil, ErrInvalidNonce
+		}
+
+		nonce = make([]byte, aead.NonceSize())
+		if _, err = rand.Read(nonce); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(nonce) != aead.NonceSize() {
+		return nil, ErrInvalidNonce
+	}
+
+	switch a.Nonce {
+	case NoncePrefix:
+		return aead.Seal(nonce, nonce, a.data, a.AssociatedData), nil
+	case NonceSuffix:
+		return append(aead.Seal(nil, nonce, a.data, a.AssociatedData), nonce...), nil
+	case NonceSeparate:
+		return aead.Seal(nil, nonce, a.data, a.AssociatedData), nil
+	default:
+		return nil, ErrUnknownNonceLayout
+	}
+}
+
+// EncodeString returns the wrapped plaintext as a string.
+func (a AEAD) EncodeString() (string, error) {
+	if a.data == nil {
+		return "", ErrNoData
+	}
+	return string(a.data), nil
+}
+
+func (a AEAD) aead() (cipher.AEAD, error) {
+	switch a.Cipher {
+	case AESGCMCipher:
+		block, err := aes.NewCipher(a.Key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	case ChaCha20Poly1305Cipher:
+		return chacha20poly1305.New(a.Key)
+	default:
+		return nil, ErrUnknownCipher
+	}
+}
+
+func (a AEAD) clone() *AEAD {
+	return &AEAD{
+		Cipher:         a.Cipher,
+		Key:            a.Key,
+		Nonce:          a.Nonce,
+		IV:             a.IV,
+		AssociatedData: a.AssociatedData,
+	}
+}
+
+// Keys read from files or the environment are raw bytes unless their contents are
+// explicitly prefixed with hex: or b64:, which allows text keys to be stored without
+// passing them on the command line. Keys in the option itself are decoded by Bytes.
+func keyFromOptions(opts Options) (key []byte, err error) {
+	if key, err = opts.Bytes("key"); err != nil || key == nil {
+		return key, err
+	}
+
+	source, _, _ := strings.Cut(opts["key"], ":")
+	switch strings.ToLower(source) {
+	case "env", "file":
+		text := strings.TrimSpace(string(key))
+		prefix, _, _ := strings.Cut(text, ":")
+		switch strings.ToLower(prefix) {
+		case "hex", "b64", "base64":
+			return Options{"key": text}.Bytes("key")
+		}
+	}
+	return key, nil
+}
+
+func (a AEAD) validKey() bool {
+	switch a.Cipher {
+	case AESGCMCipher:
+		switch len(a.Key) {
+		case 16, 24, 32:
+			return true
+		}
+		return false
+	case ChaCha20Poly1305Cipher:
+		return len(a.Key) == chacha20poly1305.KeySize
+	default:
+		return true
+	}
+}
+
+type AEADCipher uint8
+
+func (c AEADCipher) keySizes() string {
+	if c == ChaCha20Poly1305Cipher {
+		return "32 byte"
+	}
+	return "16, 24, or 32 byte"
+}
+
+func (c AEADCipher) String() string {
+	switch c {
+	case AESGCMCipher:
+		return "AES-GCM"
+	case ChaCha20Poly1305Cipher:
+		return "ChaCha20-Poly1305"
+	default:
+		return "unknown"
+	}
+}
+
+type NonceLayout uint8
+
+func (n NonceLayout) String() string {
+	switch n {
+	case NoncePrefix:
+		return "prefix"
+	case NonceSuffix:
+		return "suffix"
+	case NonceSeparate:
+		return "separate"
+	default:
+		return "unknown"
+	}
+}
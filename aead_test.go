@@ -0,0 +1,146 @@
+package binutil_test
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bbengfort/binutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAEAD(t *testing.T) {
+	makeTestForCipher := func(alg binutil.AEADCipher, layout binutil.NonceLayout, iv []byte) func(t *testing.T) {
+		return func(t *testing.T) {
+			aead := binutil.NewAEAD(alg, rand(32))
+			aead.Nonce = layout
+			aead.IV = iv
+			aead.AssociatedData = []byte("associated data")
+
+			for _, fixture := range fixtures() {
+				es, err := aead.DecodeString(string(fixture.data))
+				require.NoError(t, err, "could not decode plaintext for fixture %q", fixture.name)
+
+				ciphertext, err := es.EncodeBinary()
+				require.NoError(t, err, "could not seal plaintext for fixture %q", fixture.name)
+				require.NotEqual(t, fixture.data, ciphertext, "expected ciphertext to differ from plaintext for fixture %q", fixture.name)
+
+				eb, err := aead.DecodeBinary(ciphertext)
+				require.NoError(t, err, "could not open ciphertext for fixture %q", fixture.name)
+
+				plaintext, err := eb.EncodeString()
+				require.NoError(t, err, "could not encode plaintext for fixture %q", fixture.name)
+				require.Equal(t, string(fixture.data), plaintext, "expected plaintext to be unchanged for fixture %q", fixture.name)
+
+				// Tampering with the ciphertext must cause open to fail
+				ciphertext[len(ciphertext)/2] ^= 0x42
+				_, err = aead.DecodeBinary(ciphertext)
+				require.Error(t, err, "expected tampered ciphertext to fail for fixture %q", fixture.name)
+			}
+		}
+	}
+
+	t.Run("AESGCM/Prefix", makeTestForCipher(binutil.AESGCMCipher, binutil.NoncePrefix, nil))
+	t.Run("AESGCM/Suffix", makeTestForCipher(binutil.AESGCMCipher, binutil.NonceSuffix, nil))
+	t.Run("AESGCM/Separate", makeTestForCipher(binutil.AESGCMCipher, binutil.NonceSeparate, rand(12)))
+	t.Run("ChaCha20Poly1305/Prefix", makeTestForCipher(binutil.ChaCha20Poly1305Cipher, binutil.NoncePrefix, nil))
+	t.Run("ChaCha20Poly1305/Suffix", makeTestForCipher(binutil.ChaCha20Poly1305Cipher, binutil.NonceSuffix, nil))
+	t.Run("ChaCha20Poly1305/Separate", makeTestForCipher(binutil.ChaCha20Poly1305Cipher, binutil.NonceSeparate, rand(12)))
+}
+
+func TestAEADWrongAssociatedData(t *testing.T) {
+	key := rand(16)
+	seal := binutil.NewAEAD(binutil.AESGCMCipher, key)
+	seal.AssociatedData = []byte("row:42")
+
+	es, err := seal.DecodeString("secret")
+	require.NoError(t, err)
+	ciphertext, err := es.EncodeBinary()
+	require.NoError(t, err)
+
+	open := binutil.NewAEAD(binutil.AESGCMCipher, key)
+	open.AssociatedData = []byte("row:43")
+	_, err = open.DecodeBinary(ciphertext)
+	require.Error(t, err, "expected mismatched associated data to fail")
+
+	_, err = open.DecodeBinary(ciphertext[:8])
+	require.ErrorIs(t, err, binutil.ErrCiphertextTooShort)
+}
+
+func TestRegisteredAEAD(t *testing.T) {
+	key := rand(32)
+	t.Setenv("BINUTIL_TEST_KEY", "hex:"+hex.EncodeToString(key))
+
+	path := filepath.Join(t.TempDir(), "data.key")
+	require.NoError(t, os.WriteFile(path, key, 0600))
+
+	// The same key loaded from the environment and from a file must be compatible
+	encrypt, err := binutil.New("aesgcm:key=env:BINUTIL_TEST_KEY,aad=hello", "b64")
+	require.NoError(t, err, "could not create encryption pipeline")
+
+	decrypt, err := binutil.New("b64", "aes-gcm:key=file:"+path+",aad=hello")
+	require.NoError(t, err, "could not create decryption pipeline")
+
+	ciphertext, err := encrypt.Str2Str("the eagle has landed")
+	require.NoError(t, err, "could not encrypt plaintext")
+
+	plaintext, err := decrypt.Str2Str(ciphertext)
+	require.NoError(t, err, "could not decrypt ciphertext")
+	require.Equal(t, "the eagle has landed", plaintext)
+
+	_, err = binutil.NewDecoder("chacha20poly1305")
+	require.ErrorIs(t, err, binutil.ErrNoKey)
+
+	_, err = binutil.NewDecoder("chacha20poly1305:key=env:BINUTIL_TEST_KEY,nonce=sideways")
+	require.ErrorIs(t, err, binutil.ErrUnknownNonceLayout)
+
+	// A fixed nonce would be reused for every value sealed with the prefix or suffix layout
+	for _, layout := range []string{"prefix", "suffix"} {
+		_, err = binutil.NewDecoder("aesgcm:key=env:BINUTIL_TEST_KEY,iv=hex:000102030405060708090a0b,nonce=" + layout)
+		require.ErrorIs(t, err, binutil.ErrFixedNonce, "expected iv to be rejected with the %s layout", layout)
+	}
+
+	_, err = binutil.NewDecoder("aesgcm:key=env:BINUTIL_TEST_KEY,iv=hex:000102030405060708090a0b")
+	require.ErrorIs(t, err, binutil.ErrFixedNonce, "expected iv to be rejected with the default layout")
+
+	_, err = binutil.NewDecoder("aesgcm:key=env:BINUTIL_TEST_KEY,iv=hex:000102030405060708090a0b,nonce=separate")
+	require.NoError(t, err)
+
+	_, err = binutil.NewDecoder("chacha20poly1305:key=hex:0102")
+	require.ErrorIs(t, err, binutil.ErrInvalidKey, "expected an invalid key size to error")
+}
+
+func TestAEADKeys(t *testing.T) {
+	// A 32 character passphrase that is also valid hex or base64 is used as a raw key
+	for _, passphrase := range []string{"0123456789abcdef0123456789abcdef", "abcdefghijklmnopqrstuvwxyzABCDEF"} {
+		t.Setenv("BINUTIL_TEST_KEY", passphrase)
+		dec, err := binutil.NewDecoder("aesgcm:key=env:BINUTIL_TEST_KEY")
+		require.NoError(t, err)
+		require.Equal(t, []byte(passphrase), dec.(*binutil.AEAD).Key)
+	}
+
+	// Keys are only decoded with an explicit prefix
+	key := rand(32)
+	for _, encoded := range []string{"hex:" + hex.EncodeToString(key), "b64:" + base64.StdEncoding.EncodeToString(key)} {
+		t.Setenv("BINUTIL_TEST_KEY", encoded+"\n")
+		dec, err := binutil.NewDecoder("chacha20poly1305:key=env:BINUTIL_TEST_KEY")
+		require.NoError(t, err)
+		require.Equal(t, key, dec.(*binutil.AEAD).Key)
+
+		dec, err = binutil.NewDecoder("chacha20poly1305:key=" + encoded)
+		require.NoError(t, err)
+		require.Equal(t, key, dec.(*binutil.AEAD).Key)
+	}
+
+	// A key that is decoded explicitly is not decoded again
+	passphrase := []byte("0123456789abcdef0123456789abcdef")
+	dec, err := binutil.NewDecoder("aesgcm:key=hex:" + hex.EncodeToString(passphrase))
+	require.NoError(t, err)
+	require.Equal(t, passphrase, dec.(*binutil.AEAD).Key)
+
+	t.Setenv("BINUTIL_TEST_KEY", hex.EncodeToString(rand(32)))
+	_, err = binutil.NewDecoder("chacha20poly1305:key=env:BINUTIL_TEST_KEY")
+	require.ErrorIs(t, err, binutil.ErrInvalidKey, "expected an unprefixed hex key to be used as 64 raw bytes")
+}
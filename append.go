@@ -0,0 +1,144 @@
+package binutil
+
+import "sync"
+
+// AppendEncoder is implemented by decoders that can encode binary data into their string
+// representation without allocating an intermediate Encoder; the string representation
+// is appended to dst and the extended buffer is returned.
+type AppendEncoder interface {
+	AppendEncode(dst, src []byte) ([]byte, error)
+}
+
+// AppendDecoder is implemented by decoders that can decode their string representation
+// (as bytes) into binary data without allocating an intermediate Encoder; the binary
+// data is appended to dst and the extended buffer is returned.
+type AppendDecoder interface {
+	AppendDecode(dst, src []byte) ([]byte, error)
+}
+
+// Buffers larger than this are not returned to the pool so that a single large input
+// does not pin memory for the lifetime of the process.
+const maxPooledBuffer = 64 * 1024
+
+var buffers = sync.Pool{
+	New: func() any {
+		buf := make([]byte, 0, 256)
+		return &buf
+	},
+}
+
+func getBuffer() *[]byte {
+	return buffers.Get().(*[]byte)
+}
+
+func putBuffer(buf *[]byte) {
+	if cap(*buf) <= maxPooledBuffer {
+		*buf = (*buf)[:0]
+		buffers.Put(buf)
+	}
+}
+
+// Extend the buffer by n bytes, returning the extended buffer and the n bytes at its end.
+func grow(dst []byte, n int) ([]byte, []byte) {
+	size := len(dst)
+	if cap(dst)-size < n {
+		buf := make([]byte, size, 2*cap(dst)+n)
+		copy(buf, dst)
+		dst = buf
+	}
+	dst = dst[:size+n]
+	return dst, dst[size:]
+}
+
+// Run the pipeline without allocating Encoders if every step implements AppendEncoder
+// and AppendDecoder (or is a fused step of such decoders) and was described with its
+// fixed length (if any). Intermediate binary data is passed through the steps in pooled
+// buffers, checking the fixed length of each step in place of decoding it and
+// converting the data of fused steps. If the fast path cannot be used or any step
+// fails, ok is false and the pipeline should be run step by step so that errors are
+// reported exactly as the step by step conversion reports them.
+func (p *Pipeline) runFast(in []byte, str string, fromString, toString bool) (out []byte, outStr string, ok bool) {
+	if len(p.steps) == 0 || (!fromString && in == nil) {
+		return nil, "", false
+	}
+
+	for i, step := range p.steps {
+		if p.info(i) == nil {
+			return nil, "", false
+		}
+
+		if fused, isFused := step.(*fusedStep); isFused {
+			if !appendable(fused.from) || !appendable(fused.to) {
+				return nil, "", false
+			}
+		} else if !appendable(step) {
+			return nil, "", false
+		}
+	}
+
+	// Data is written to the buffers in turn so that the input of each operation is
+	// never the buffer that it writes to.
+	var (
+		bufs = [2]*[]byte{getBuffer(), getBuffer()}
+		next int
+		err  error
+	)
+
+	defer putBuffer(bufs[0])
+	defer putBuffer(bufs[1])
+
+	if fromString {
+		*bufs[next] = append((*bufs[next])[:0], str...)
+		in, next = *bufs[next], 1-next
+	}
+
+	last := len(p.steps) - 1
+	for i, step := range p.steps {
+		fused, isFused := step.(*fusedStep)
+		if i == 0 && fromString {
+			decoder := step
+			if isFused {
+				decoder = fused.from
+			}
+
+			if *bufs[next], err = decoder.(AppendDecoder).AppendDecode((*bufs[next])[:0], in); err != nil {
+				return nil, "", false
+			}
+			in, next = *bufs[next], 1-next
+		} else if length := p.infos[i].Length; length > 0 && len(in) != length {
+			return nil, "", false
+		}
+
+		if isFused {
+			if *bufs[next], err = fused.convert((*bufs[next])[:0], in); err != nil {
+				return nil, "", false
+			}
+			in, next = *bufs[next], 1-next
+		}
+
+		if i == last && toString {
+			encoder := step
+			if isFused {
+				encoder = fused.to
+			}
+
+			if *bufs[next], err = encoder.(AppendEncoder).AppendEncode((*bufs[next])[:0], in); err != nil {
+				return nil, "", false
+			}
+			return nil, string(*bufs[next]), true
+		}
+	}
+
+	out = make([]byte, len(in))
+	copy(out, in)
+	return out, "", true
+}
+
+func appendable(step Decoder) bool {
+	if _, ok := step.(AppendEncoder); !ok {
+		return false
+	}
+
+	_, ok := step.(AppendDecoder)
+	return ok
+}
@@ -0,0 +1,201 @@
+package binutil_test
+
+import (
+	"testing"
+
+	"github.com/bbengfort/binutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendEncoders(t *testing.T) {
+	data := rand(16)
+
+	steps := []binutil.Decoder{
+		&binutil.Hex{},
+		binutil.NewBase64(binutil.B64SchemeStd),
+		binutil.NewBase64(binutil.B64SchemeRawStd),
+		binutil.NewBase64(binutil.B64SchemeURL),
+		binutil.NewBase64(binutil.B64SchemeRawURL),
+		&binutil.ULID{},
+		&binutil.UUID{},
+	}
+
+	for _, step := range steps {
+		enc, err := step.DecodeBinary(data)
+		require.NoError(t, err)
+
+		expected, err := enc.EncodeString()
+		require.NoError(t, err)
+
+		// The encoding is appended to the destination
+		out, err := step.(binutil.AppendEncoder).AppendEncode([]byte("prefix:"), data)
+		require.NoError(t, err, "could not append encode %T", step)
+		require.Equal(t, "prefix:"+expected, string(out), "append encode %T", step)
+
+		out, err = step.(binutil.AppendDecoder).AppendDecode([]byte("prefix:"), []byte(expected))
+		require.NoError(t, err, "could not append decode %T", step)
+		require.Equal(t, append([]byte("prefix:"), data...), out, "append decode %T", step)
+
+		// Invalid input returns an error and the unmodified destination
+		out, err = step.(binutil.AppendDecoder).AppendDecode([]byte("prefix:"), []byte("!!"))
+		require.Error(t, err, "expected an error decoding %T", step)
+		require.Equal(t, "prefix:", string(out))
+	}
+
+	_, err := (binutil.ULID{}).AppendEncode(nil, data[:8])
+	require.Error(t, err)
+
+	_, err = (binutil.UUID{}).AppendEncode(nil, data[:8])
+	require.Error(t, err)
+}
+
+func TestPipelineFastPath(t *testing.T) {
+	testCases := []struct {
+		steps []string
+		in    string
+	}{
+		{[]string{"ulid", "uuid"}, "01H3W3MX9A4AFNW55R0MNMQR6Y"},
+		{[]string{"uuid", "ulid"}, "0188f83a-752a-229f-5e14-b8052b4be0de"},
+		{[]string{"hex", "b64"}, "DEADBEEF"},
+		{[]string{"b64", "b64url", "hex"}, "3q2+7w=="},
+		{[]string{"hex", "uuid"}, "0188f83a752a229f5e14b8052b4be0de"},
+		{[]string{"hex", "uuid"}, "0188f83a"},
+		{[]string{"hex", "b64"}, ""},
+		{[]string{"hex", "b64"}, "zz"},
+		{[]string{"ulid", "hex"}, "01H3W3MX9A4AFNW55R0MNMQR6y"},
+	}
+
+	for i, tc := range testCases {
+		fast := mustPipeline(t, tc.steps...)
+
+		// Decoder instances are not described so they disable the fast path
+		slowSteps := make([]any, 0, len(tc.steps))
+		for _, step := range tc.steps {
+			slowSteps = append(slowSteps, slowDecoder(step))
+		}
+
+		slow, err := binutil.New(slowSteps...)
+		require.NoError(t, err)
+
+		expected, expectedErr := slow.Str2Str(tc.in)
+		actual, err := fast.Str2Str(tc.in)
+		require.Equal(t, expectedErr, err, "test case %d failed", i)
+		require.Equal(t, expected, actual, "test case %d failed", i)
+
+		expectedBin, expectedErr := slow.Str2Bin(tc.in)
+		actualBin, err := fast.Str2Bin(tc.in)
+		require.Equal(t, expectedErr, err, "test case %d failed", i)
+		require.Equal(t, expectedBin, actualBin, "test case %d failed", i)
+
+		if expectedBin != nil {
+			expected, expectedErr = slow.Bin2Str(expectedBin)
+			actual, err = fast.Bin2Str(expectedBin)
+			require.Equal(t, expectedErr, err, "test case %d failed", i)
+			require.Equal(t, expected, actual, "test case %d failed", i)
+		}
+	}
+}
+
+func mustPipeline(t testing.TB, steps ...string) *binutil.Pipeline {
+	args := make([]any, 0, len(steps))
+	for _, step := range steps {
+		args = append(args, step)
+	}
+
+	pipe, err := binutil.New(args...)
+	require.NoError(t, err)
+	return pipe
+}
+
+// Create a Decoder instance for the step, which disables the fast path.
+func slowDecoder(name string) binutil.Decoder {
+	dec, err := binutil.NewDecoder(name)
+	if err != nil {
+		panic(err)
+	}
+	return dec
+}
+
+func BenchmarkPipeline(b *testing.B) {
+	id := []byte{0x01, 0x88, 0xf8, 0x3a, 0x75, 0x2a, 0x22, 0x9f, 0x5e, 0x14, 0xb8, 0x05, 0x2b, 0x4b, 0xe0, 0xde}
+
+	b.Run("Bin2Str/hex", func(b *testing.B) {
+		pipe := mustPipeline(b, "hex")
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := pipe.Bin2Str(id); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Bin2Str/hex/slow", func(b *testing.B) {
+		pipe, _ := binutil.New(&binutil.Hex{})
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := pipe.Bin2Str(id); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Bin2Str/ulid", func(b *testing.B) {
+		pipe := mustPipeline(b, "ulid")
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := pipe.Bin2Str(id); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Bin2Str/ulid/slow", func(b *testing.B) {
+		pipe, _ := binutil.New(&binutil.ULID{})
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := pipe.Bin2Str(id); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Str2Str/ulid-uuid", func(b *testing.B) {
+		pipe := mustPipeline(b, "ulid", "uuid")
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := pipe.Str2Str("01H3W3MX9A4AFNW55R0MNMQR6Y"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Str2Str/ulid-uuid/slow", func(b *testing.B) {
+		pipe, _ := binutil.New(&binutil.ULID{}, &binutil.UUID{})
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := pipe.Str2Str("01H3W3MX9A4AFNW55R0MNMQR6Y"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Str2Str/hex-b64", func(b *testing.B) {
+		pipe := mustPipeline(b, "hex", "b64")
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := pipe.Str2Str("0188f83a752a229f5e14b8052b4be0de"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Str2Str/hex-b64/slow", func(b *testing.B) {
+		pipe, _ := binutil.New(&binutil.Hex{}, binutil.NewBase64(binutil.B64SchemeStd))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := pipe.Str2Str("0188f83a752a229f5e14b8052b4be0de"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
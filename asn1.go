@@ -0,0 +1,594 @@
+package binutil
+
+import (
+	"bufio"
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+func init() {
+	RegisterDecoder(ASN1Decoder, func() Decoder { return &ASN1{} }, "der")
+	DescribeDecoder(ASN1Decoder, Info{Description: "structural dump of ASN.1 DER data", Category: StructuredCategory, Capabilities: Bidirectional})
+}
+
+const ASN1Decoder = "asn1"
+
+// ASN1 implements the encoder and decoder interface for DER encoded ASN.1 data. The
+// binary representation is the DER encoding and the string representation is an
+// indented structural dump similar to openssl asn1parse, with one element per line:
+//
+//	SEQUENCE (len 13)
+//	  OBJECT IDENTIFIER (len 9) 1.2.840.113549.1.1.11 (sha256WithRSAEncryption)
+//	  NULL (len 0)
+//
+// Each line contains the tag (universal tags by name, otherwise [n], [APPLICATION n],
+// [PRIVATE n], or [UNIVERSAL n]), an annotation with the content length, and the value.
+// Strings are quoted, integers are decimal, bit strings are the number of unused bits
+// followed by hex, and all other primitive values are hex. OCTET STRING and BIT STRING
+// values that contain DER structures are dumped as nested elements. The dump can be
+// decoded back into DER; lengths and OID names are derived so they are ignored.
+type ASN1 struct {
+	data []byte
+}
+
+var (
+	_ Encoder = &ASN1{}
+	_ Decoder = &ASN1{}
+)
+
+// DecodeBinary ensures the input is well formed DER and wraps it for encoding.
+func (a ASN1) DecodeBinary(in []byte) (_ Encoder, err error) {
+	if _, err = parseDER(in); err != nil {
+		return nil, err
+	}
+	return &ASN1{data: in}, nil
+}
+
+// DecodeString parses a structural dump and builds the DER encoding from it.
+func (a ASN1) DecodeString(in string) (_ Encoder, err error) {
+	var nodes []*asn1Node
+	if nodes, err = parseASN1Dump(in); err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, 0)
+	for _, node := range nodes {
+		var der []byte
+		if der, err = node.marshal(); err != nil {
+			return nil, err
+		}
+		data = append(data, der...)
+	}
+	return &ASN1{data: data}, nil
+}
+
+// EncodeBinary returns the DER encoded data.
+func (a ASN1) EncodeBinary() ([]byte, error) {
+	if a.data != nil {
+		return a.data, nil
+	}
+	return nil, ErrNoData
+}
+
+// EncodeString renders the DER encoded data as an indented structural dump.
+func (a ASN1) EncodeString() (_ string, err error) {
+	if a.data == nil {
+		return "", ErrNoData
+	}
+
+	var elems []asn1.RawValue
+	if elems, err = parseDER(a.data); err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	for _, elem := range elems {
+		dumpASN1(&out, elem, 0)
+	}
+	return out.String(), nil
+}
+
+// Universal tag names used in the structural dump.
+var asn1TagNames = map[int]string{
+	0:                       "EOC",
+	asn1.TagBoolean:         "BOOLEAN",
+	asn1.TagInteger:         "INTEGER",
+	asn1.TagBitString:       "BIT STRING",
+	asn1.TagOctetString:     "OCTET STRING",
+	asn1.TagNull:            "NULL",
+	asn1.TagOID:             "OBJECT IDENTIFIER",
+	7:                       "ObjectDescriptor",
+	8:                       "EXTERNAL",
+	9:                       "REAL",
+	asn1.TagEnum:            "ENUMERATED",
+	asn1.TagUTF8String:      "UTF8String",
+	13:                      "RELATIVE-OID",
+	asn1.TagSequence:        "SEQUENCE",
+	asn1.TagSet:             "SET",
+	asn1.TagNumericString:   "NumericString",
+	asn1.TagPrintableString: "PrintableString",
+	asn1.TagT61String:       "T61String",
+	21:                      "VideotexString",
+	asn1.TagIA5String:       "IA5String",
+	asn1.TagUTCTime:         "UTCTime",
+	asn1.TagGeneralizedTime: "GeneralizedTime",
+	25:                      "GraphicString",
+	26:                      "VisibleString",
+	asn1.TagGeneralString:   "GeneralString",
+	28:                      "UniversalString",
+	asn1.TagBMPString:       "BMPString",
+}
+
+// Parse all of the top level elements in the DER data.
+func parseDER(data []byte) (elems []asn1.RawValue, err error) {
+	for len(data) > 0 {
+		var elem asn1.RawValue
+		if data, err = asn1.Unmarshal(data, &elem); err != nil {
+			return nil, err
+		}
+		elems = append(elems, elem)
+	}
+	return elems, nil
+}
+
+// Returns the nested elements if the content of a primitive string type is itself a
+// DER structure; only constructed universal types are considered to avoid treating
+// arbitrary bytes as encapsulated data.
+func encapsulated(content []byte) []asn1.RawValue {
+	elems, err := parseDER(content)
+	if err != nil || len(elems) == 0 {
+		return nil
+	}
+
+	if first := elems[0]; first.Class != asn1.ClassUniversal || !first.IsCompound {
+		return nil
+	}
+	return elems
+}
+
+func dumpASN1(out *strings.Builder, elem asn1.RawValue, depth int) {
+	indent := strings.Repeat("  ", depth)
+	name := asn1TagName(elem.Class, elem.Tag)
+
+	if elem.IsCompound {
+		children, err := parseDER(elem.Bytes)
+		if err != nil {
+			// Malformed constructed content is dumped as hex rather than failing.
+			fmt.Fprintf(out, "%s%s (len %d, constructed) %x\n", indent, name, len(elem.Bytes), elem.Bytes)
+			return
+		}
+
+		if elem.Class == asn1.ClassUniversal && (elem.Tag == asn1.TagSequence || elem.Tag == asn1.TagSet) {
+			fmt.Fprintf(out, "%s%s (len %d)\n", indent, name, len(elem.Bytes))
+		} else {
+			fmt.Fprintf(out, "%s%s (len %d, constructed)\n", indent, name, len(elem.Bytes))
+		}
+
+		for _, child := range children {
+			dumpASN1(out, child, depth+1)
+		}
+		return
+	}
+
+	if elem.Class == asn1.ClassUniversal {
+		switch elem.Tag {
+		case asn1.TagOctetString:
+			if children := encapsulated(elem.Bytes); children != nil {
+				fmt.Fprintf(out, "%s%s (len %d, encapsulates)\n", indent, name, len(elem.Bytes))
+				for _, child := range children {
+					dumpASN1(out, child, depth+1)
+				}
+				return
+			}
+		case asn1.TagBitString:
+			if len(elem.Bytes) > 1 && elem.Bytes[0] == 0 {
+				if children := encapsulated(elem.Bytes[1:]); children != nil {
+					fmt.Fprintf(out, "%s%s (len %d, encapsulates)\n", indent, name, len(elem.Bytes))
+					for _, child := range children {
+						dumpASN1(out, child, depth+1)
+					}
+					return
+				}
+			}
+		}
+	}
+
+	value := asn1Value(elem)
+	if value != "" {
+		value = " " + value
+	}
+	fmt.Fprintf(out, "%s%s (len %d)%s\n", indent, name, len(elem.Bytes), value)
+}
+
+func asn1TagName(class, tag int) string {
+	switch class {
+	case asn1.ClassUniversal:
+		if name, ok := asn1TagNames[tag]; ok {
+			return name
+		}
+		return fmt.Sprintf("[UNIVERSAL %d]", tag)
+	case asn1.ClassApplication:
+		return fmt.Sprintf("[APPLICATION %d]", tag)
+	case asn1.ClassContextSpecific:
+		return fmt.Sprintf("[%d]", tag)
+	default:
+		return fmt.Sprintf("[PRIVATE %d]", tag)
+	}
+}
+
+// Render the value of a primitive element for the structural dump.
+func asn1Value(elem asn1.RawValue) string {
+	if elem.Class != asn1.ClassUniversal {
+		return hex.EncodeToString(elem.Bytes)
+	}
+
+	switch elem.Tag {
+	case asn1.TagBoolean:
+		if len(elem.Bytes) == 1 {
+			if elem.Bytes[0] == 0 {
+				return "FALSE"
+			}
+			return "TRUE"
+		}
+	case asn1.TagInteger, asn1.TagEnum:
+		if len(elem.Bytes) > 0 {
+			n := new(big.Int).SetBytes(elem.Bytes)
+			if elem.Bytes[0]&0x80 != 0 {
+				n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(len(elem.Bytes)*8)))
+			}
+			return n.String()
+		}
+	case asn1.TagOID:
+		var oid asn1.ObjectIdentifier
+		if _, err := asn1.Unmarshal(elem.FullBytes, &oid); err == nil {
+			if name := OIDName(oid.String()); name != "" {
+				return fmt.Sprintf("%s (%s)", oid, name)
+			}
+			return oid.String()
+		}
+	case asn1.TagNull:
+		return ""
+	case asn1.TagBitString:
+		if len(elem.Bytes) > 0 {
+			return fmt.Sprintf("%d %x", elem.Bytes[0], elem.Bytes[1:])
+		}
+	case asn1.TagBMPString:
+		if len(elem.Bytes)%2 == 0 {
+			units := make([]uint16, 0, len(elem.Bytes)/2)
+			for i := 0; i < len(elem.Bytes); i += 2 {
+				units = append(units, uint16(elem.Bytes[i])<<8|uint16(elem.Bytes[i+1]))
+			}
+			return strconv.Quote(string(utf16.Decode(units)))
+		}
+	case 7, asn1.TagUTF8String, asn1.TagNumericString, asn1.TagPrintableString, asn1.TagT61String, 21, asn1.TagIA5String, asn1.TagUTCTime, asn1.TagGeneralizedTime, 25, 26, asn1.TagGeneralString:
+		if utf8.Valid(elem.Bytes) {
+			return strconv.Quote(string(elem.Bytes))
+		}
+	}
+	return hex.EncodeToString(elem.Bytes)
+}
+
+type asn1Node struct {
+	line        int
+	depth       int
+	class       int
+	tag         int
+	constructed bool
+	value       string
+	children    []*asn1Node
+}
+
+// Parse the structural dump into a tree of nodes using the indentation of each line.
+func parseASN1Dump(in string) (roots []*asn1Node, err error) {
+	var stack []*asn1Node
+	scanner := bufio.NewScanner(strings.NewReader(in))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(in)+1)
+
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		trimmed := strings.TrimLeft(line, " ")
+		spaces := len(line) - len(trimmed)
+		if spaces%2 != 0 {
+			return nil, fmt.Errorf("asn1 line %d: indentation must be a multiple of two spaces", lineno)
+		}
+
+		node := &asn1Node{line: lineno, depth: spaces / 2}
+		if err = node.parseHeader(trimmed); err != nil {
+			return nil, fmt.Errorf("asn1 line %d: %w", lineno, err)
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].depth >= node.depth {
+			stack = stack[:len(stack)-1]
+		}
+
+		if len(stack) == 0 {
+			if node.depth != 0 {
+				return nil, fmt.Errorf("asn1 line %d: unexpected indentation", lineno)
+			}
+			roots = append(roots, node)
+		} else {
+			parent := stack[len(stack)-1]
+			if node.depth != parent.depth+1 {
+				return nil, fmt.Errorf("asn1 line %d: unexpected indentation", lineno)
+			}
+
+			if parent.value != "" {
+				return nil, fmt.Errorf("asn1 line %d: element with a value cannot contain nested elements", lineno)
+			}
+			parent.children = append(parent.children, node)
+		}
+		stack = append(stack, node)
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(roots) == 0 {
+		return nil, ErrNoData
+	}
+	return roots, nil
+}
+
+// Parse the tag, annotation, and value from a single line of the structural dump.
+func (n *asn1Node) parseHeader(line string) (err error) {
+	n.class = asn1.ClassUniversal
+	if strings.HasPrefix(line, "[") {
+		end := strings.Index(line, "]")
+		if end < 0 {
+			return fmt.Errorf("unterminated tag %q", line)
+		}
+
+		fields := strings.Fields(line[1:end])
+		switch {
+		case len(fields) == 1:
+			n.class = asn1.ClassContextSpecific
+		case len(fields) == 2 && fields[0] == "APPLICATION":
+			n.class = asn1.ClassApplication
+		case len(fields) == 2 && fields[0] == "PRIVATE":
+			n.class = asn1.ClassPrivate
+		case len(fields) == 2 && fields[0] == "UNIVERSAL":
+			n.class = asn1.ClassUniversal
+		default:
+			return fmt.Errorf("unknown tag %q", line[:end+1])
+		}
+
+		if n.tag, err = strconv.Atoi(fields[len(fields)-1]); err != nil || n.tag < 0 {
+			return fmt.Errorf("invalid tag number %q", line[:end+1])
+		}
+		line = line[end+1:]
+	} else {
+		// Match the longest universal tag name since some names contain spaces.
+		match := ""
+		for tag, name := range asn1TagNames {
+			if strings.HasPrefix(line, name) && (len(line) == len(name) || line[len(name)] == ' ') && len(name) > len(match) {
+				match, n.tag = name, tag
+			}
+		}
+
+		if match == "" {
+			return fmt.Errorf("unknown tag in %q", line)
+		}
+		line = line[len(match):]
+	}
+
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "(len") {
+		end := strings.Index(line, ")")
+		if end < 0 {
+			return fmt.Errorf("unterminated annotation %q", line)
+		}
+
+		n.constructed = strings.Contains(line[:end], "constructed")
+		line = strings.TrimSpace(line[end+1:])
+	}
+
+	if n.class == asn1.ClassUniversal && (n.tag == asn1.TagSequence || n.tag == asn1.TagSet) {
+		n.constructed = true
+	}
+
+	n.value = line
+	return nil
+}
+
+// Marshal the node and its children into DER.
+func (n *asn1Node) marshal() (_ []byte, err error) {
+	raw := asn1.RawValue{Class: n.class, Tag: n.tag}
+	encapsulates := n.class == asn1.ClassUniversal && (n.tag == asn1.TagOctetString || n.tag == asn1.TagBitString)
+
+	if len(n.children) > 0 || (n.constructed && n.value == "") {
+		raw.IsCompound = !encapsulates
+		raw.Bytes = make([]byte, 0)
+		if encapsulates && n.tag == asn1.TagBitString {
+			raw.Bytes = append(raw.Bytes, 0)
+		}
+
+		for _, child := range n.children {
+			var der []byte
+			if der, err = child.marshal(); err != nil {
+				return nil, err
+			}
+			raw.Bytes = append(raw.Bytes, der...)
+		}
+	} else {
+		raw.IsCompound = n.constructed
+		if raw.Bytes, err = n.content(); err != nil {
+			return nil, fmt.Errorf("asn1 line %d: %w", n.line, err)
+		}
+	}
+
+	return asn1.Marshal(raw)
+}
+
+// Parse the value of a primitive node into its content bytes.
+func (n *asn1Node) content() (_ []byte, err error) {
+	value := n.value
+	if strings.HasPrefix(value, "\"") {
+		if value, err = strconv.Unquote(value); err != nil {
+			return nil, fmt.Errorf("invalid string value: %w", err)
+		}
+
+		if n.class == asn1.ClassUniversal && n.tag == asn1.TagBMPString {
+			data := make([]byte, 0, len(value)*2)
+			for _, unit := range utf16.Encode([]rune(value)) {
+				data = append(data, byte(unit>>8), byte(unit))
+			}
+			return data, nil
+		}
+		return []byte(value), nil
+	}
+
+	if n.class == asn1.ClassUniversal {
+		switch n.tag {
+		case asn1.TagBoolean:
+			switch strings.ToUpper(value) {
+			case "TRUE":
+				return []byte{0xff}, nil
+			case "FALSE":
+				return []byte{0x00}, nil
+			}
+		case asn1.TagInteger, asn1.TagEnum:
+			num, ok := new(big.Int).SetString(value, 0)
+			if !ok {
+				return nil, fmt.Errorf("invalid integer %q", value)
+			}
+			return contentOf(asn1.Marshal(num))
+		case asn1.TagOID:
+			var oid asn1.ObjectIdentifier
+			fields := strings.Fields(value)
+			if len(fields) == 0 {
+				return nil, fmt.Errorf("missing object identifier")
+			}
+
+			for _, arc := range strings.Split(fields[0], ".") {
+				var num int
+				if num, err = strconv.Atoi(arc); err != nil {
+					return nil, fmt.Errorf("invalid object identifier %q", fields[0])
+				}
+				oid = append(oid, num)
+			}
+			return contentOf(asn1.Marshal(oid))
+		case asn1.TagNull:
+			if value != "" {
+				return nil, fmt.Errorf("NULL cannot have a value")
+			}
+			return []byte{}, nil
+		case asn1.TagBitString:
+			fields := strings.Fields(value)
+			if len(fields) == 0 {
+				return nil, fmt.Errorf("missing unused bits for BIT STRING")
+			}
+
+			var unused uint64
+			if unused, err = strconv.ParseUint(fields[0], 10, 3); err != nil {
+				return nil, fmt.Errorf("invalid unused bits %q", fields[0])
+			}
+
+			var data []byte
+			if data, err = hex.DecodeString(strings.Join(fields[1:], "")); err != nil {
+				return nil, err
+			}
+			return append([]byte{byte(unused)}, data...), nil
+		}
+	}
+
+	return hex.DecodeString(strings.Join(strings.Fields(value), ""))
+}
+
+// Strip the header from a marshaled DER element to get its content bytes.
+func contentOf(der []byte, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	var raw asn1.RawValue
+	if _, err = asn1.Unmarshal(der, &raw); err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), raw.Bytes...), nil
+}
+
+// OIDName returns the well known name of the dotted object identifier or an empty
+// string if the object identifier is not known.
+func OIDName(oid string) string {
+	return oidNames[oid]
+}
+
+var oidNames = map[string]string{
+	// Public key and signature algorithms
+	"1.2.840.113549.1.1.1":   "rsaEncryption",
+	"1.2.840.113549.1.1.5":   "sha1WithRSAEncryption",
+	"1.2.840.113549.1.1.10":  "rsassaPss",
+	"1.2.840.113549.1.1.11":  "sha256WithRSAEncryption",
+	"1.2.840.113549.1.1.12":  "sha384WithRSAEncryption",
+	"1.2.840.113549.1.1.13":  "sha512WithRSAEncryption",
+	"1.2.840.10045.2.1":      "ecPublicKey",
+	"1.2.840.10045.4.3.2":    "ecdsa-with-SHA256",
+	"1.2.840.10045.4.3.3":    "ecdsa-with-SHA384",
+	"1.2.840.10045.4.3.4":    "ecdsa-with-SHA512",
+	"1.2.840.10045.3.1.7":    "prime256v1",
+	"1.3.132.0.34":           "secp384r1",
+	"1.3.132.0.35":           "secp521r1",
+	"1.3.101.110":            "X25519",
+	"1.3.101.112":            "ED25519",
+	"2.16.840.1.101.3.4.2.1": "sha256",
+	"2.16.840.1.101.3.4.2.2": "sha384",
+	"2.16.840.1.101.3.4.2.3": "sha512",
+	"1.3.14.3.2.26":          "sha1",
+
+	// PKCS #7 and #9
+	"1.2.840.113549.1.7.1":  "pkcs7-data",
+	"1.2.840.113549.1.7.2":  "pkcs7-signedData",
+	"1.2.840.113549.1.9.1":  "emailAddress",
+	"1.2.840.113549.1.9.14": "extensionRequest",
+
+	// X.500 attribute types
+	"2.5.4.3":                    "commonName",
+	"2.5.4.4":                    "surname",
+	"2.5.4.5":                    "serialNumber",
+	"2.5.4.6":                    "countryName",
+	"2.5.4.7":                    "localityName",
+	"2.5.4.8":                    "stateOrProvinceName",
+	"2.5.4.9":                    "streetAddress",
+	"2.5.4.10":                   "organizationName",
+	"2.5.4.11":                   "organizationalUnitName",
+	"2.5.4.42":                   "givenName",
+	"0.9.2342.19200300.100.1.25": "domainComponent",
+
+	// X.509 certificate extensions
+	"2.5.29.14":               "subjectKeyIdentifier",
+	"2.5.29.15":               "keyUsage",
+	"2.5.29.17":               "subjectAltName",
+	"2.5.29.18":               "issuerAltName",
+	"2.5.29.19":               "basicConstraints",
+	"2.5.29.30":               "nameConstraints",
+	"2.5.29.31":               "cRLDistributionPoints",
+	"2.5.29.32":               "certificatePolicies",
+	"2.5.29.35":               "authorityKeyIdentifier",
+	"2.5.29.37":               "extKeyUsage",
+	"1.3.6.1.5.5.7.1.1":       "authorityInfoAccess",
+	"1.3.6.1.4.1.11129.2.4.2": "ctPrecertificateSCTs",
+	"2.5.29.32.0":             "anyPolicy",
+	"2.23.140.1.2.1":          "domain-validated",
+	"2.23.140.1.2.2":          "organization-validated",
+
+	// Extended key usages and access methods
+	"1.3.6.1.5.5.7.3.1":  "serverAuth",
+	"1.3.6.1.5.5.7.3.2":  "clientAuth",
+	"1.3.6.1.5.5.7.3.3":  "codeSigning",
+	"1.3.6.1.5.5.7.3.4":  "emailProtection",
+	"1.3.6.1.5.5.7.3.8":  "timeStamping",
+	"1.3.6.1.5.5.7.3.9":  "OCSPSigning",
+	"1.3.6.1.5.5.7.48.1": "ocsp",
+	"1.3.6.1.5.5.7.48.2": "caIssuers",
+}
@@ -0,0 +1,129 @@
+package binutil_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	crand "crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/bbengfort/binutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestASN1(t *testing.T) {
+	der := certificate(t)
+	a := &binutil.ASN1{}
+
+	eb, err := a.DecodeBinary(der)
+	require.NoError(t, err, "could not decode certificate der")
+
+	dump, err := eb.EncodeString()
+	require.NoError(t, err, "could not dump certificate der")
+	require.Contains(t, dump, "OBJECT IDENTIFIER (len 8) 1.2.840.10045.4.3.2 (ecdsa-with-SHA256)")
+	require.Contains(t, dump, "PrintableString (len 11) \"example.com\"")
+	require.Contains(t, dump, "OBJECT IDENTIFIER (len 3) 2.5.29.17 (subjectAltName)")
+	require.Contains(t, dump, "OCTET STRING (len 21, encapsulates)")
+	require.Contains(t, dump, "[2] (len 11) 6578616d706c652e636f6d")
+
+	es, err := a.DecodeString(dump)
+	require.NoError(t, err, "could not parse certificate dump")
+
+	data, err := es.EncodeBinary()
+	require.NoError(t, err, "could not encode der from dump")
+	require.Equal(t, der, data, "expected dump to round trip to the original der")
+
+	_, err = x509.ParseCertificate(data)
+	require.NoError(t, err, "expected round tripped der to be a valid certificate")
+}
+
+func TestASN1DecodeString(t *testing.T) {
+	dump := `SEQUENCE
+  BOOLEAN TRUE
+  INTEGER -129
+  INTEGER 0x0100
+  ENUMERATED (len 1) 3
+  NULL
+  OBJECT IDENTIFIER 1.2.840.113549.1.1.11 (ignored comment)
+  UTF8String "héllo"
+  BMPString "hi"
+  BIT STRING 4 f0
+  OCTET STRING de ad be ef
+  [0] (len 3, constructed)
+    INTEGER 2
+  [1] 0a0b
+  [APPLICATION 3] (len 0, constructed)
+  [PRIVATE 40] ff
+  SET
+`
+	pipe, err := binutil.New("asn1", "hex")
+	require.NoError(t, err)
+
+	out, err := pipe.Str2Str(dump)
+	require.NoError(t, err, "could not build der from the dump")
+	require.Equal(t, "30440101ff0202ff7f020201000a0103050006092a864886f70d01010b0c0668c3a96c6c6f1e0400680069030204f00404deadbeefa00302010281020a0b6300df2801ff3100", out)
+
+	// Convert back into a dump and ensure the values are rendered as expected
+	pipe, err = binutil.New("hex", "asn1")
+	require.NoError(t, err)
+
+	rendered, err := pipe.Str2Str(out)
+	require.NoError(t, err)
+	require.Contains(t, rendered, "  INTEGER (len 2) -129\n")
+	require.Contains(t, rendered, "  INTEGER (len 2) 256\n")
+	require.Contains(t, rendered, "  OBJECT IDENTIFIER (len 9) 1.2.840.113549.1.1.11 (sha256WithRSAEncryption)\n")
+	require.Contains(t, rendered, "  UTF8String (len 6) \"héllo\"\n")
+	require.Contains(t, rendered, "  BMPString (len 4) \"hi\"\n")
+	require.Contains(t, rendered, "  BIT STRING (len 2) 4 f0\n")
+	require.Contains(t, rendered, "  [0] (len 3, constructed)\n    INTEGER (len 1) 2\n")
+	require.Contains(t, rendered, "  [APPLICATION 3] (len 0, constructed)\n")
+	require.Contains(t, rendered, "  [PRIVATE 40] (len 1) ff\n")
+	require.Contains(t, rendered, "  SET (len 0)\n")
+
+	for _, invalid := range []string{
+		"NOT A TAG 12",
+		"SEQUENCE\n   INTEGER 1",
+		"  INTEGER 1",
+		"INTEGER one",
+		"INTEGER 1\n  INTEGER 2",
+		"OBJECT IDENTIFIER 1.two.3",
+		"[3",
+		"",
+	} {
+		_, err := (&binutil.ASN1{}).DecodeString(invalid)
+		require.Error(t, err, "expected dump %q to be invalid", invalid)
+	}
+
+	_, err = (&binutil.ASN1{}).DecodeBinary([]byte{0x30, 0x05, 0x01})
+	require.Error(t, err, "expected truncated der to be invalid")
+}
+
+func TestOIDName(t *testing.T) {
+	require.Equal(t, "commonName", binutil.OIDName("2.5.4.3"))
+	require.Equal(t, "", binutil.OIDName("1.2.3.4.5"))
+}
+
+// Create a self-signed certificate for tests that handle DER data.
+func certificate(t *testing.T) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), crand.Reader)
+	require.NoError(t, err, "could not generate ecdsa key")
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "example.com", Organization: []string{"Example, Inc."}},
+		NotBefore:    time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:     time.Date(2033, 6, 1, 0, 0, 0, 0, time.UTC),
+		DNSNames:     []string{"example.com"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(crand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err, "could not create certificate")
+	return der
+}
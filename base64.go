@@ -19,6 +19,12 @@ func init() {
 	RegisterDecoder(RawBase64Decoder, func() Decoder { return NewBase64(B64SchemeRawStd) }, "base64raw", "b64raw")
 	RegisterDecoder(URLBase64Decoder, func() Decoder { return NewBase64(B64SchemeURL) }, "base64url", "b64url")
 	RegisterDecoder(RawURLBase64Decoder, func() Decoder { return NewBase64(B64SchemeRawURL) }, "base64rawurl", "b64rawurl")
+
+	DescribeDecoder(Base64Decoder, Info{Description: "standard base64 encoding with padding (RFC 4648)", Category: RadixCategory, Capabilities: Bidirectional})
+	DescribeDecoder(StdBase64Decoder, Info{Description: "standard base64 encoding with padding (RFC 4648)", Category: RadixCategory, Capabilities: Bidirectional})
+	DescribeDecoder(RawBase64Decoder, Info{Description: "standard base64 encoding without padding", Category: RadixCategory, Capabilities: Bidirectional})
+	DescribeDecoder(URLBase64Decoder, Info{Description: "url and filename safe base64 encoding with padding", Category: RadixCategory, Capabilities: Bidirectional})
+	DescribeDecoder(RawURLBase64Decoder, Info{Description: "url and filename safe base64 encoding without padding", Category: RadixCategory, Capabilities: Bidirectional})
 }
 
 const (
@@ -45,8 +51,10 @@ type Base64 struct {
 }
 
 var (
-	_ Encoder = &Base64{}
-	_ Decoder = &Base64{}
+	_ Encoder       = &Base64{}
+	_ Decoder       = &Base64{}
+	_ AppendEncoder = &Base64{}
+	_ AppendDecoder = &Base64{}
 )
 
 // DecodeBinary returns a new Base64 object with the wrapped data, ready to be encoded
@@ -108,8 +116,56 @@ func (b Base64) EncodeString() (string, error) {
 	}
 }
 
+// AppendEncode appends the base64 encoding of src to dst.
+func (b Base64) AppendEncode(dst, src []byte) (_ []byte, err error) {
+	var enc *base64.Encoding
+	if enc, err = b.Scheme.encoding(); err != nil {
+		return dst, err
+	}
+
+	var tail []byte
+	dst, tail = grow(dst, enc.EncodedLen(len(src)))
+	enc.Encode(tail, src)
+	return dst, nil
+}
+
+// AppendDecode appends the bytes represented by the base64 string src to dst.
+func (b Base64) AppendDecode(dst, src []byte) (_ []byte, err error) {
+	var enc *base64.Encoding
+	if enc, err = b.Scheme.encoding(); err != nil {
+		return dst, err
+	}
+
+	var (
+		tail []byte
+		n    int
+	)
+
+	size := len(dst)
+	dst, tail = grow(dst, enc.DecodedLen(len(src)))
+	if n, err = enc.Decode(tail, src); err != nil {
+		return dst[:size], err
+	}
+	return dst[:size+n], nil
+}
+
 type Base64Scheme uint8
 
+func (b Base64Scheme) encoding() (*base64.Encoding, error) {
+	switch b {
+	case B64SchemeStd:
+		return base64.StdEncoding, nil
+	case B64SchemeRawStd:
+		return base64.RawStdEncoding, nil
+	case B64SchemeURL:
+		return base64.URLEncoding, nil
+	case B64SchemeRawURL:
+		return base64.RawURLEncoding, nil
+	default:
+		return nil, ErrUnknownB64Scheme
+	}
+}
+
 func (b Base64Scheme) String() string {
 	switch b {
 	case B64SchemeStd:
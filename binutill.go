@@ -1,10 +1,10 @@
 package binutil
 
 import (
+	"context"
 	"fmt"
-	"sort"
 	"strings"
-	"sync"
+	"time"
 )
 
 // An encoder is an object that can marshal either a binary or a string representation
@@ -27,110 +27,78 @@ type Decoder interface {
 
 // Pipelines manage transformers converting data from the input type to the output type.
 type Pipeline struct {
-	steps []Decoder
+	steps    []Decoder
+	infos    []*Info
+	limits   Limits
+	registry *Registry
 }
 
-// New returns a pipeline that can convert binary and string data.
+// New returns a pipeline that can convert binary and string data. Steps are either
+// decoder specifications that are looked up in the registry (the DefaultRegistry unless
+// the WithRegistry option is specified) or Decoder instances. The pipeline is validated
+// and the first problem found by Validate is returned.
 func New(steps ...any) (_ *Pipeline, err error) {
-	decoders := make([]Decoder, 0, len(steps))
+	conf := newPipelineConfig(steps)
+	pipe := &Pipeline{
+		steps:    make([]Decoder, 0, len(steps)),
+		infos:    make([]*Info, 0, len(steps)),
+		limits:   conf.limits,
+		registry: conf.registry,
+	}
+
 	for _, step := range steps {
-		var decoder Decoder
+		var (
+			decoder Decoder
+			info    *Info
+		)
+
 		switch t := step.(type) {
 		case string:
-			if decoder, err = NewDecoder(t); err != nil {
+			if decoder, err = conf.registry.New(t); err != nil {
 				return nil, err
 			}
+
+			name, _, _ := strings.Cut(t, ":")
+			if described, ok := conf.registry.Info(name); ok {
+				info = &described
+			}
 		case Decoder:
 			decoder = t
+		case PipelineOption:
+			continue
 		default:
 			return nil, ErrUnknownStepType
 		}
-		decoders = append(decoders, decoder)
+
+		pipe.steps = append(pipe.steps, decoder)
+		pipe.infos = append(pipe.infos, info)
 	}
-	return &Pipeline{steps: decoders}, nil
+
+	if diags := pipe.Validate(); len(diags) > 0 {
+		return nil, diags[0]
+	}
+	return pipe, nil
 }
 
 // Bin2Bin transforms binary input data into binary output data by decoding the binary
 // data at each step of the pipeline and encoding it to bytes before passing it to the
 // next step in the pipeline.
 func (p *Pipeline) Bin2Bin(in []byte) (_ []byte, err error) {
-	if len(p.steps) == 0 {
-		return nil, ErrEmptyPipeline
-	}
-
-	for i, step := range p.steps {
-		var encoder Encoder
-		if encoder, err = step.DecodeBinary(in); err != nil {
-			return nil, fmt.Errorf("could not decode binary in step %d: %w", i, err)
-		}
-
-		if in, err = encoder.EncodeBinary(); err != nil {
-			return nil, fmt.Errorf("could not encode binary in step %d: %w", i, err)
-		}
-	}
-
-	return in, nil
+	return p.Bin2BinContext(context.Background(), in)
 }
 
 // Bin2Str transforms binary input data into a string representation by decoding the
 // binary input data at each step of the pipeline and encoding it to bytes before
 // passing it to the next step in the pipeline. The final step is encoded as a str.
 func (p *Pipeline) Bin2Str(in []byte) (out string, err error) {
-	if len(p.steps) == 0 {
-		return "", ErrEmptyPipeline
-	}
-
-	lastStep := len(p.steps) - 1
-	for i, step := range p.steps {
-		var encoder Encoder
-		if encoder, err = step.DecodeBinary(in); err != nil {
-			return "", fmt.Errorf("could not decode binary in step %d: %w", i, err)
-		}
-
-		if i == lastStep {
-			if out, err = encoder.EncodeString(); err != nil {
-				return "", fmt.Errorf("could not encode string in step %d: %w", i, err)
-			}
-		} else {
-			if in, err = encoder.EncodeBinary(); err != nil {
-				return "", fmt.Errorf("could not encode binary in step %d: %w", i, err)
-			}
-		}
-	}
-
-	return out, nil
+	return p.Bin2StrContext(context.Background(), in)
 }
 
 // Str2Bin transforms binary input data into binary output data by decoding the string
 // in the first step of the pipeline then encoding it to bytes before passing it to each
 // additional step to decode as bytes.
 func (p *Pipeline) Str2Bin(in string) (out []byte, err error) {
-	if len(p.steps) == 0 {
-		return nil, ErrEmptyPipeline
-	}
-
-	var encoder Encoder
-	if encoder, err = p.steps[0].DecodeString(in); err != nil {
-		return nil, fmt.Errorf("could not decode string in step %d: %w", 0, err)
-	}
-
-	if out, err = encoder.EncodeBinary(); err != nil {
-		return nil, fmt.Errorf("could not encode binary in step %d: %w", 0, err)
-	}
-
-	if len(p.steps) > 1 {
-		for i, step := range p.steps[1:] {
-			if encoder, err = step.DecodeBinary(out); err != nil {
-				return nil, fmt.Errorf("could not decode binary in step %d: %w", i, err)
-			}
-
-			if out, err = encoder.EncodeBinary(); err != nil {
-				return nil, fmt.Errorf("could not encode binary in step %d: %w", i, err)
-			}
-		}
-	}
-
-	return out, nil
+	return p.Str2BinContext(context.Background(), in)
 }
 
 // Str2Str transforms string input data into a different string representation by
@@ -138,102 +106,125 @@ func (p *Pipeline) Str2Bin(in string) (out []byte, err error) {
 // bytes and decoding as binary for each additional step of the pipeline The final step
 // is encoded as a string.
 func (p *Pipeline) Str2Str(in string) (out string, err error) {
-	if len(p.steps) == 0 {
-		return "", ErrEmptyPipeline
-	}
+	return p.Str2StrContext(context.Background(), in)
+}
 
-	var encoder Encoder
-	if encoder, err = p.steps[0].DecodeString(in); err != nil {
-		return "", fmt.Errorf("could not decode string in step %d: %w", 0, err)
+// Bin2BinContext is Bin2Bin that stops between steps if the context is cancelled.
+func (p *Pipeline) Bin2BinContext(ctx context.Context, in []byte) (_ []byte, err error) {
+	var out []byte
+	if out, _, err = p.run(ctx, in, "", false, false, nil); err != nil {
+		return nil, err
 	}
+	return out, nil
+}
 
-	if len(p.steps) > 1 {
-		var data []byte
-		if data, err = encoder.EncodeBinary(); err != nil {
-			return "", fmt.Errorf("could not encode binary in step %d: %w", 0, err)
-		}
-
-		lastStep := len(p.steps) - 2
-		for i, step := range p.steps[1:] {
-			if encoder, err = step.DecodeBinary(data); err != nil {
-				return "", fmt.Errorf("could not decode binary in step %d: %w", i, err)
-			}
-
-			if i == lastStep {
-				if out, err = encoder.EncodeString(); err != nil {
-					return "", fmt.Errorf("could not encode string in step %d: %w", i, err)
-				}
-			} else {
-				if data, err = encoder.EncodeBinary(); err != nil {
-					return "", fmt.Errorf("could not encode binary in step %d: %w", i, err)
-				}
-			}
-		}
-	} else {
-		if out, err = encoder.EncodeString(); err != nil {
-			return "", fmt.Errorf("could not encode string in step %d: %w", 0, err)
-		}
+// Bin2StrContext is Bin2Str that stops between steps if the context is cancelled.
+func (p *Pipeline) Bin2StrContext(ctx context.Context, in []byte) (out string, err error) {
+	if _, out, err = p.run(ctx, in, "", false, true, nil); err != nil {
+		return "", err
 	}
-
 	return out, nil
 }
 
-var (
-	decmu    sync.RWMutex
-	decoders map[string]decoder
-)
-
-// DecoderConstructors are functions that create a new Decoder ready for use.
-type DecoderConstructor func() Decoder
+// Str2BinContext is Str2Bin that stops between steps if the context is cancelled.
+func (p *Pipeline) Str2BinContext(ctx context.Context, in string) (out []byte, err error) {
+	if out, _, err = p.run(ctx, nil, in, true, false, nil); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
 
-type decoder struct {
-	constructor DecoderConstructor
-	alias       bool
+// Str2StrContext is Str2Str that stops between steps if the context is cancelled.
+func (p *Pipeline) Str2StrContext(ctx context.Context, in string) (out string, err error) {
+	if _, out, err = p.run(ctx, nil, in, true, true, nil); err != nil {
+		return "", err
+	}
+	return out, nil
 }
 
-// Register a decoder constructor so that the decoder can be referenced by the name
-// suplied and users can instantiate it directly from the type name. Note that names are
-// case insensitive so MyDecoder is the same as mydecoder.
-func RegisterDecoder(name string, constructor DecoderConstructor, aliases ...string) {
-	// All lookups are case insensitive
-	name = strings.TrimSpace(strings.ToLower(name))
-
-	decmu.Lock()
-	defer decmu.Unlock()
-	if decoders == nil {
-		decoders = make(map[string]decoder)
+// Run the steps of the pipeline, decoding the string input in the first step if
+// fromString is true (otherwise the binary input) and encoding the string output in the
+// last step if toString is true (otherwise the binary output). Every intermediate step
+// decodes and encodes binary data. The context and the limits of the pipeline are
+// checked before and after every step. If trace is not nil it is called with the
+// result of every step that was run, including a step that failed.
+func (p *Pipeline) run(ctx context.Context, in []byte, str string, fromString, toString bool, trace func(StepTrace)) (out []byte, outStr string, err error) {
+	if len(p.steps) == 0 {
+		return nil, "", ErrEmptyPipeline
 	}
 
-	decoders[name] = decoder{constructor, false}
-	for _, alias := range aliases {
-		decoders[alias] = decoder{constructor, true}
+	if trace == nil && p.limits == (Limits{}) && ctx.Err() == nil {
+		var ok bool
+		if out, outStr, ok = p.runFast(in, str, fromString, toString); ok {
+			return out, outStr, nil
+		}
 	}
-}
 
-// Create a decoder by name rather than by directly instantiating one.
-func NewDecoder(name string) (Decoder, error) {
-	// All lookups are case insensitive
-	name = strings.TrimSpace(strings.ToLower(name))
+	size := len(in)
+	if fromString {
+		size = len(str)
+	}
 
-	decmu.RLock()
-	defer decmu.RUnlock()
-	if decoder, ok := decoders[name]; ok {
-		return decoder.constructor(), nil
+	check := p.limits.start(size)
+	if err = check.input(size); err != nil {
+		return nil, "", err
 	}
-	return nil, fmt.Errorf("no registered decoder with the name %q", name)
-}
 
-func DecoderNames() []string {
-	decmu.RLock()
-	defer decmu.RUnlock()
+	last := len(p.steps) - 1
+	for i, step := range p.steps {
+		if err = check.context(ctx, i); err != nil {
+			return nil, "", err
+		}
+
+		var (
+			encoder Encoder
+			started = time.Now()
+			stepIn  = size
+		)
+
+		if err = func() error {
+			if i == 0 && fromString {
+				if encoder, err = step.DecodeString(str); err != nil {
+					return fmt.Errorf("could not decode string in step %d: %w", i, err)
+				}
+			} else {
+				if encoder, err = step.DecodeBinary(in); err != nil {
+					return fmt.Errorf("could not decode binary in step %d: %w", i, err)
+				}
+			}
+
+			if i == last && toString {
+				if outStr, err = encoder.EncodeString(); err != nil {
+					return fmt.Errorf("could not encode string in step %d: %w", i, err)
+				}
+				size = len(outStr)
+			} else {
+				if in, err = encoder.EncodeBinary(); err != nil {
+					return fmt.Errorf("could not encode binary in step %d: %w", i, err)
+				}
+				size = len(in)
+			}
+			return check.output(i, size)
+		}(); err != nil {
+			if trace != nil {
+				trace(StepTrace{Step: i, Name: p.name(i), Input: stepIn, Duration: time.Since(started), Err: err})
+			}
+			return nil, "", err
+		}
 
-	out := make([]string, 0, len(decoders))
-	for name, decoder := range decoders {
-		if !decoder.alias {
-			out = append(out, name)
+		if trace != nil {
+			st := StepTrace{Step: i, Name: p.name(i), Input: stepIn, Output: size, Duration: time.Since(started)}
+			if i == last && toString {
+				st.Preview = preview([]byte(outStr))
+			} else {
+				st.Preview = preview(in)
+			}
+			trace(st)
 		}
 	}
 
-	sort.Strings(out)
-	return out
+	if err = check.context(ctx, last); err != nil {
+		return nil, "", err
+	}
+	return in, outStr, nil
 }
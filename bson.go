@@ -0,0 +1,146 @@
+package binutil
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+func init() {
+	RegisterOptionsDecoder(BSONDecoder, func(opts Options) (Decoder, error) { return NewBSONFromOptions(opts) })
+	DescribeDecoder(BSONDecoder, Info{Description: "BSON documents as MongoDB Extended JSON", Category: StructuredCategory, Capabilities: Bidirectional | Lossy, Options: []string{"relaxed", "pretty"}})
+}
+
+const BSONDecoder = "bson"
+
+// NewBSONFromOptions creates a BSON step from the step specification options. The
+// relaxed flag renders relaxed rather than canonical Extended JSON and the pretty flag
+// indents the Extended JSON string representation.
+func NewBSONFromOptions(opts Options) (_ *BSON, err error) {
+	b := &BSON{}
+	if b.Relaxed, err = opts.Bool("relaxed"); err != nil {
+		return nil, err
+	}
+
+	if b.Pretty, err = opts.Bool("pretty"); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// BSON implements the encoder and decoder interface for BSON documents. The binary
+// representation is the BSON document and the string representation is MongoDB
+// Extended JSON v2. Canonical Extended JSON (the default) preserves the type of every
+// value, e.g. ObjectIDs, Decimal128, int32 vs int64, binary subtypes, and datetimes,
+// so that a document can be edited and encoded back into BSON without changes; relaxed
+// Extended JSON is easier to read but numeric types may not round trip. When decoding
+// strings both forms are accepted, as is the {"$uuid": "..."} shorthand for binary
+// subtype 4 values. The field order of the document is always preserved.
+type BSON struct {
+	Relaxed bool
+	Pretty  bool
+	data    bson.Raw
+}
+
+var (
+	_ Encoder = &BSON{}
+	_ Decoder = &BSON{}
+)
+
+// DecodeBinary validates the BSON document.
+func (b BSON) DecodeBinary(in []byte) (_ Encoder, err error) {
+	out := &BSON{Relaxed: b.Relaxed, Pretty: b.Pretty, data: bson.Raw(in)}
+	if err = out.data.Validate(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DecodeString parses canonical or relaxed Extended JSON into a BSON document.
+func (b BSON) DecodeString(in string) (_ Encoder, err error) {
+	out := &BSON{Relaxed: b.Relaxed, Pretty: b.Pretty}
+	if err = bson.UnmarshalExtJSON([]byte(in), false, &out.data); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EncodeBinary returns the BSON document.
+func (b BSON) EncodeBinary() ([]byte, error) {
+	if b.data == nil {
+		return nil, ErrNoData
+	}
+	return b.data, nil
+}
+
+// EncodeString renders the document as canonical or relaxed Extended JSON.
+func (b BSON) EncodeString() (_ string, err error) {
+	if b.data == nil {
+		return "", ErrNoData
+	}
+
+	var data []byte
+	if b.Pretty {
+		data, err = bson.MarshalExtJSONIndent(b.data, !b.Relaxed, false, "", "  ")
+	} else {
+		data, err = bson.MarshalExtJSON(b.data, !b.Relaxed, false)
+	}
+
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Document returns the raw BSON document.
+func (b BSON) Document() bson.Raw {
+	return b.data
+}
+
+// UUIDFromBSON converts a binary subtype 4 value into a UUID. Legacy subtype 3 values
+// are rejected because their byte order depends on the driver that wrote them (e.g. the
+// C# and Java drivers reverse the bytes), so they cannot be converted reliably.
+func UUIDFromBSON(bin primitive.Binary) (*UUID, error) {
+	if bin.Subtype != bson.TypeBinaryUUID {
+		return nil, fmt.Errorf("cannot convert binary subtype 0x%02x to a uuid", bin.Subtype)
+	}
+
+	u := &UUID{}
+	if err := u.UUID.UnmarshalBinary(bin.Data); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// BSONBinary returns the UUID as a binary subtype 4 value.
+func (u UUID) BSONBinary() primitive.Binary {
+	return primitive.Binary{Subtype: bson.TypeBinaryUUID, Data: append([]byte(nil), u.UUID[:]...)}
+}
+
+// MarshalBSONValue implements bson.ValueMarshaler so that UUIDs are stored as binary
+// subtype 4 values rather than as an array of bytes.
+func (u UUID) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return bson.TypeBinary, bsoncore.AppendBinary(nil, bson.TypeBinaryUUID, u.UUID[:]), nil
+}
+
+// UnmarshalBSONValue implements bson.ValueUnmarshaler to decode binary subtype 4 values.
+func (u *UUID) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	if t != bson.TypeBinary {
+		return fmt.Errorf("cannot unmarshal bson %s into a uuid", t)
+	}
+
+	subtype, bin, _, ok := bsoncore.ReadBinary(data)
+	if !ok {
+		return bsoncore.NewInsufficientBytesError(data, data)
+	}
+
+	parsed, err := UUIDFromBSON(primitive.Binary{Subtype: subtype, Data: bin})
+	if err != nil {
+		return err
+	}
+	u.UUID = parsed.UUID
+	return nil
+}
@@ -0,0 +1,90 @@
+package binutil_test
+
+import (
+	"testing"
+
+	"github.com/bbengfort/binutil"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const bsonFixture = `{"_id":{"$oid":"64a1b2c3d4e5f6a7b8c9d0e1"},"price":{"$numberDecimal":"19.99"},"count":{"$numberInt":"3"},"total":{"$numberLong":"42"},"created":{"$date":{"$numberLong":"1688208000000"}},"owner":{"$binary":{"base64":"Eq3hq4hMQ9ahKUIrGsjOaw==","subType":"04"}},"blob":{"$binary":{"base64":"AQID","subType":"00"}}}`
+
+func TestBSON(t *testing.T) {
+	pipe, err := binutil.New("bson")
+	require.NoError(t, err, "could not create bson pipeline")
+
+	data, err := pipe.Str2Bin(bsonFixture)
+	require.NoError(t, err, "could not parse canonical extended json")
+
+	doc := bson.Raw(data)
+	require.Equal(t, bson.TypeObjectID, doc.Lookup("_id").Type)
+	require.Equal(t, bson.TypeDecimal128, doc.Lookup("price").Type)
+	require.Equal(t, bson.TypeInt32, doc.Lookup("count").Type)
+	require.Equal(t, bson.TypeInt64, doc.Lookup("total").Type)
+	require.Equal(t, bson.TypeDateTime, doc.Lookup("created").Type)
+
+	s, err := pipe.Bin2Str(data)
+	require.NoError(t, err, "could not render canonical extended json")
+	require.Equal(t, bsonFixture, s, "expected canonical extended json to round trip")
+
+	_, err = pipe.Bin2Str([]byte{0x05, 0x00, 0x00, 0x00})
+	require.Error(t, err, "expected invalid bson to be rejected")
+}
+
+func TestBSONRelaxed(t *testing.T) {
+	pipe, err := binutil.New("bson:relaxed")
+	require.NoError(t, err, "could not create bson pipeline")
+
+	s, err := pipe.Str2Str(bsonFixture)
+	require.NoError(t, err, "could not convert canonical to relaxed extended json")
+	require.Contains(t, s, `"count":3`)
+	require.Contains(t, s, `"created":{"$date":"2023-07-01T10:40:00Z"}`)
+
+	// The $uuid shorthand is parsed as binary subtype 4
+	data, err := pipe.Str2Bin(`{"id": {"$uuid": "12ade1ab-884c-43d6-a129-422b1ac8ce6b"}}`)
+	require.NoError(t, err, "could not parse $uuid shorthand")
+
+	subtype, _, ok := bson.Raw(data).Lookup("id").BinaryOK()
+	require.True(t, ok, "expected id to be a binary value")
+	require.Equal(t, bson.TypeBinaryUUID, subtype)
+}
+
+func TestBSONUUID(t *testing.T) {
+	type record struct {
+		ID    binutil.UUID `bson:"id"`
+		Other binutil.UUID `bson:"other"`
+	}
+
+	in := record{
+		ID:    binutil.UUID{UUID: uuid.MustParse("12ade1ab-884c-43d6-a129-422b1ac8ce6b")},
+		Other: binutil.UUID{UUID: uuid.New()},
+	}
+
+	data, err := bson.Marshal(in)
+	require.NoError(t, err, "could not marshal uuid fields")
+
+	subtype, raw := bson.Raw(data).Lookup("id").Binary()
+	require.Equal(t, bson.TypeBinaryUUID, subtype)
+	require.Equal(t, in.ID.UUID[:], raw)
+
+	out := record{}
+	require.NoError(t, bson.Unmarshal(data, &out), "could not unmarshal uuid fields")
+	require.Equal(t, in, out)
+
+	u, err := binutil.UUIDFromBSON(in.ID.BSONBinary())
+	require.NoError(t, err, "could not convert binary to uuid")
+	require.Equal(t, in.ID, *u)
+
+	_, err = binutil.UUIDFromBSON(primitive.Binary{Subtype: 0x00, Data: raw})
+	require.Error(t, err, "expected generic binary subtype to be rejected")
+
+	_, err = binutil.UUIDFromBSON(primitive.Binary{Subtype: bson.TypeBinaryUUIDOld, Data: raw})
+	require.Error(t, err, "expected legacy uuid subtype with a driver specific byte order to be rejected")
+
+	data, err = bson.Marshal(bson.M{"id": 42})
+	require.NoError(t, err)
+	require.Error(t, bson.Unmarshal(data, &out), "expected non-binary values to be rejected")
+}
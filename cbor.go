@@ -0,0 +1,124 @@
+package binutil
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// CBOR string formats: JSON or diagnostic notation as described in RFC 8949 section 8.
+const (
+	CBORJSON CBORFormat = iota
+	CBORDiagnostic
+)
+
+func init() {
+	RegisterOptionsDecoder(CBORDecoder, func(opts Options) (Decoder, error) { return NewCBORFromOptions(opts) })
+	DescribeDecoder(CBORDecoder, Info{Description: "CBOR data as diagnostic notation or JSON", Category: StructuredCategory, Capabilities: Bidirectional | Lossy, Options: []string{"format", "pretty"}})
+}
+
+const CBORDecoder = "cbor"
+
+// Deterministic (RFC 8949 core deterministic) encoding is used when converting JSON into
+// CBOR so that the output is reproducible.
+var cborEncMode, _ = cbor.CoreDetEncOptions().EncMode()
+
+// NewCBORFromOptions creates a CBOR step from the step specification options. The
+// format option selects the string representation (json or diag, json by default so that
+// the string representation can be decoded again) and the pretty flag indents the JSON
+// string representation.
+func NewCBORFromOptions(opts Options) (_ *CBOR, err error) {
+	c := &CBOR{}
+	switch strings.ToLower(opts.Get("format", "json")) {
+	case "diag", "diagnostic", "edn":
+		c.Format = CBORDiagnostic
+	case "json":
+		c.Format = CBORJSON
+	default:
+		return nil, errors.New("unknown cbor format, use diag or json")
+	}
+
+	if c.Pretty, err = opts.Bool("pretty"); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// CBOR implements the encoder and decoder interface for CBOR data, e.g. COSE and
+// WebAuthn payloads. The binary representation is the CBOR encoding and the string
+// representation is either JSON, where tags are rendered as objects with the tag number
+// and value, or diagnostic notation (including tags). Only JSON, which is a subset of
+// diagnostic notation, can be decoded from a string; it is encoded into CBOR using the
+// core deterministic encoding rules.
+type CBOR struct {
+	Format CBORFormat
+	Pretty bool
+	data   []byte
+	value  any
+}
+
+var (
+	_ Encoder = &CBOR{}
+	_ Decoder = &CBOR{}
+)
+
+// DecodeBinary decodes the CBOR data into a generic value.
+func (c CBOR) DecodeBinary(in []byte) (_ Encoder, err error) {
+	out := &CBOR{Format: c.Format, Pretty: c.Pretty, data: in}
+	if err = cbor.Unmarshal(in, &out.value); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DecodeString parses the JSON document and encodes it as CBOR.
+func (c CBOR) DecodeString(in string) (_ Encoder, err error) {
+	out := &CBOR{Format: c.Format, Pretty: c.Pretty}
+	if out.value, err = parseJSON([]byte(in)); err != nil {
+		return nil, err
+	}
+
+	if out.data, err = cborEncMode.Marshal(out.value); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EncodeBinary returns the CBOR encoding.
+func (c CBOR) EncodeBinary() ([]byte, error) {
+	if c.data != nil {
+		return c.data, nil
+	}
+	return nil, ErrNoData
+}
+
+// EncodeString renders the CBOR data in diagnostic notation or as JSON.
+func (c CBOR) EncodeString() (string, error) {
+	if c.data == nil {
+		return "", ErrNoData
+	}
+
+	if c.Format == CBORDiagnostic {
+		return cbor.Diagnose(c.data)
+	}
+
+	data, err := marshalJSON(c.value, c.Pretty)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+type CBORFormat uint8
+
+func (f CBORFormat) String() string {
+	switch f {
+	case CBORDiagnostic:
+		return "diag"
+	case CBORJSON:
+		return "json"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,76 @@
+package binutil_test
+
+import (
+	"testing"
+
+	"github.com/bbengfort/binutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCBOR(t *testing.T) {
+	pipe, err := binutil.New("cbor:format=diag")
+	require.NoError(t, err, "could not create cbor pipeline")
+
+	// {"a": 1, "b": [true, null, h'0102']}
+	data := []byte{0xa2, 0x61, 0x61, 0x01, 0x61, 0x62, 0x83, 0xf5, 0xf6, 0x42, 0x01, 0x02}
+	s, err := pipe.Bin2Str(data)
+	require.NoError(t, err, "could not convert cbor to diagnostic notation")
+	require.Equal(t, `{"a": 1, "b": [true, null, h'0102']}`, s)
+
+	// Tags are preserved in diagnostic notation: 1(1700000000)
+	s, err = pipe.Bin2Str([]byte{0xc1, 0x1a, 0x65, 0x53, 0xf1, 0x00})
+	require.NoError(t, err, "could not convert tagged cbor to diagnostic notation")
+	require.Equal(t, "1(1700000000)", s)
+
+	// JSON is encoded deterministically with sorted keys and the smallest integers
+	out, err := pipe.Str2Bin(`{"b": [true, null], "a": 1}`)
+	require.NoError(t, err, "could not convert json to cbor")
+	require.Equal(t, []byte{0xa2, 0x61, 0x61, 0x01, 0x61, 0x62, 0x82, 0xf5, 0xf6}, out)
+
+	_, err = pipe.Str2Bin(`{"a": h'01'}`)
+	require.Error(t, err, "expected diagnostic notation input to be rejected")
+
+	_, err = pipe.Bin2Str([]byte{0xff})
+	require.Error(t, err, "expected invalid cbor to be rejected")
+}
+
+func TestCBORJSON(t *testing.T) {
+	pipe, err := binutil.New("cbor")
+	require.NoError(t, err, "could not create cbor pipeline")
+
+	// The default string representation is JSON so that it can be decoded again
+	data := []byte{0xa2, 0x61, 0x61, 0x01, 0x61, 0x62, 0x82, 0xf5, 0xf6}
+	s, err := pipe.Bin2Str(data)
+	require.NoError(t, err, "could not convert cbor to json")
+	require.Equal(t, `{"a":1,"b":[true,null]}`, s)
+
+	out, err := pipe.Str2Bin(s)
+	require.NoError(t, err, "could not convert json to cbor")
+	require.Equal(t, data, out)
+
+	pipe, err = binutil.New("cbor:format=json")
+	require.NoError(t, err, "could not create cbor pipeline")
+
+	// {1: 100("x"), "k": -1}
+	data = []byte{0xa2, 0x01, 0xd8, 0x64, 0x61, 0x78, 0x61, 0x6b, 0x20}
+	s, err = pipe.Bin2Str(data)
+	require.NoError(t, err, "could not convert cbor to json")
+	require.JSONEq(t, `{"1": {"tag": 100, "value": "x"}, "k": -1}`, s)
+
+	// Epoch time tags are rendered as RFC 3339 timestamps
+	s, err = pipe.Bin2Str([]byte{0xc1, 0x1a, 0x65, 0x53, 0xf1, 0x00})
+	require.NoError(t, err, "could not convert tagged cbor to json")
+	require.Equal(t, `"2023-11-14T22:13:20Z"`, s)
+
+	// Bignums are rendered as JSON numbers without losing precision: 2(h'010000000000000000')
+	s, err = pipe.Bin2Str([]byte{0xc2, 0x49, 0x01, 0, 0, 0, 0, 0, 0, 0, 0})
+	require.NoError(t, err, "could not convert a cbor bignum to json")
+	require.Equal(t, "18446744073709551616", s)
+
+	rt, err := pipe.Str2Str(`{"x": 18446744073709551615}`)
+	require.NoError(t, err, "could not round trip json through cbor")
+	require.JSONEq(t, `{"x": 18446744073709551615}`, rt)
+
+	_, err = binutil.New("cbor:format=yaml")
+	require.Error(t, err, "expected unknown format to be rejected")
+}
@@ -0,0 +1,343 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/bbengfort/binutil"
+	"github.com/urfave/cli/v2"
+)
+
+// Error policies determine what happens when a value in a column cannot be converted.
+const (
+	failPolicy  = "fail"
+	skipPolicy  = "skip"
+	keepPolicy  = "keep"
+	blankPolicy = "blank"
+)
+
+// columnConversion describes how an output column is computed from a source column:
+// the source value is decoded to bytes with the decoder then encoded with the encoder.
+// Converted columns replace the source column and added columns are appended to the
+// row, in which case the target is the offset after the last column of the input.
+type columnConversion struct {
+	name     string
+	source   int
+	target   int
+	appended bool
+	decoder  string
+	encoder  string
+	policy   string
+}
+
+func convertCSV(c *cli.Context) (err error) {
+	if c.NArg() > 1 {
+		return cli.Exit("specify a single file to read or - for stdin", 1)
+	}
+
+	var in io.Reader = os.Stdin
+	if path := c.Args().First(); path != "" && path != "-" {
+		var f *os.File
+		if f, err = os.Open(path); err != nil {
+			return cli.Exit(err, 1)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	var delimiter rune
+	if delimiter, err = parseDelimiter(c.String("delimiter"), c.Bool("tsv")); err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	reader := csv.NewReader(in)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+
+	writer := csv.NewWriter(os.Stdout)
+	writer.Comma = delimiter
+	defer writer.Flush()
+
+	var header []string
+	if c.Bool("header") {
+		if header, err = reader.Read(); err != nil {
+			return cli.Exit(fmt.Errorf("could not read header: %w", err), 1)
+		}
+		header = append([]string(nil), header...)
+	}
+
+	var conversions []*columnConversion
+	if conversions, err = csvConversions(c, header); err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	if len(conversions) == 0 {
+		return cli.Exit("specify at least one --column or --add conversion", 1)
+	}
+
+	// Each source column is decoded once per row with its decoder pipeline and then
+	// encoded into every requested output representation with the multi pipeline.
+	decoders := make(map[string]*binutil.Pipeline)
+	encoders := make([]string, 0, len(conversions))
+	for _, conv := range conversions {
+		if err = binutil.CheckPipeline(binutil.Str2BinConversion, conv.decoder); err != nil {
+			return cli.Exit(err, 1)
+		}
+
+		if err = binutil.CheckPipeline(binutil.Bin2StrConversion, conv.encoder); err != nil {
+			return cli.Exit(err, 1)
+		}
+
+		if _, ok := decoders[conv.decoder]; !ok {
+			if decoders[conv.decoder], err = binutil.New(conv.decoder); err != nil {
+				return cli.Exit(err, 1)
+			}
+		}
+		encoders = append(encoders, conv.encoder)
+	}
+
+	var multi *binutil.MultiPipeline
+	if multi, err = binutil.NewMulti(encoders...); err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	// Added columns are aligned after the header or after the last column of each row
+	width := len(header)
+	if header != nil {
+		for _, conv := range conversions {
+			if conv.appended {
+				header = append(header, conv.name)
+			}
+		}
+
+		if err = writer.Write(header); err != nil {
+			return cli.Exit(err, 1)
+		}
+	}
+
+	skipped := 0
+rows:
+	for {
+		var record []string
+		if record, err = reader.Read(); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return cli.Exit(err, 1)
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := append([]string(nil), record...)
+		decoded := make(map[string][]byte)
+
+		for _, conv := range conversions {
+			target := conv.target
+			if conv.appended {
+				if header != nil {
+					target += width
+				} else {
+					target += len(record)
+				}
+			}
+
+			for len(row) <= target {
+				row = append(row, "")
+			}
+
+			src := ""
+			if conv.source < len(record) {
+				src = record[conv.source]
+			}
+
+			// Empty values are passed through without conversion
+			if src == "" {
+				row[target] = ""
+				continue
+			}
+
+			var out string
+			if out, err = convertValue(conv, src, decoders, multi, decoded); err != nil {
+				switch conv.policy {
+				case skipPolicy:
+					skipped++
+					continue rows
+				case keepPolicy:
+					out = src
+				case blankPolicy:
+					out = ""
+				default:
+					return cli.Exit(fmt.Errorf("line %d, column %s: %w", line, conv.name, err), 1)
+				}
+			}
+			row[target] = out
+		}
+
+		if err = writer.Write(row); err != nil {
+			return cli.Exit(err, 1)
+		}
+	}
+
+	writer.Flush()
+	if err = writer.Error(); err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	if skipped > 0 && !c.Bool("quiet") {
+		fmt.Fprintf(os.Stderr, "skipped %d rows with values that could not be converted\n", skipped)
+	}
+	return nil
+}
+
+func convertValue(conv *columnConversion, src string, decoders map[string]*binutil.Pipeline, multi *binutil.MultiPipeline, decoded map[string][]byte) (_ string, err error) {
+	key := strconv.Itoa(conv.source) + ":" + conv.decoder
+	data, ok := decoded[key]
+	if !ok {
+		if data, err = decoders[conv.decoder].Str2Bin(src); err != nil {
+			return "", err
+		}
+		decoded[key] = data
+	}
+	return multi.Bin2Str(conv.encoder, data)
+}
+
+// Parse the --column, --add, and --on-error flags into the column conversions. Columns
+// are referenced by header name or by their 1-based position.
+func csvConversions(c *cli.Context, header []string) (_ []*columnConversion, err error) {
+	defaultPolicy := failPolicy
+	policies := make(map[string]string)
+	for _, spec := range c.StringSlice("on-error") {
+		name, policy, found := strings.Cut(spec, "=")
+		if !found {
+			name, policy = "", spec
+		}
+
+		if policy, err = parsePolicy(policy); err != nil {
+			return nil, err
+		}
+
+		if name == "" {
+			defaultPolicy = policy
+		} else {
+			policies[name] = policy
+		}
+	}
+
+	policyFor := func(name string) string {
+		if policy, ok := policies[name]; ok {
+			return policy
+		}
+		return defaultPolicy
+	}
+
+	conversions := make([]*columnConversion, 0)
+	if columns := c.StringSlice("column"); len(columns) > 0 {
+		if c.String("decode") == "" || c.String("encode") == "" {
+			return nil, errors.New("encoder and decoder must be specified to convert columns")
+		}
+
+		for _, name := range columns {
+			var idx int
+			if idx, err = columnIndex(name, header); err != nil {
+				return nil, err
+			}
+
+			conversions = append(conversions, &columnConversion{
+				name:    name,
+				source:  idx,
+				target:  idx,
+				decoder: c.String("decode"),
+				encoder: c.String("encode"),
+				policy:  policyFor(name),
+			})
+		}
+	}
+
+	// Added columns are appended after the columns of the input file
+	for i, spec := range c.StringSlice("add") {
+		conv := &columnConversion{}
+		var src string
+		if conv.name, src, conv.decoder, conv.encoder, err = parseAddSpec(spec); err != nil {
+			return nil, err
+		}
+
+		if conv.source, err = columnIndex(src, header); err != nil {
+			return nil, err
+		}
+
+		conv.target, conv.appended = i, true
+		conv.policy = policyFor(conv.name)
+		conversions = append(conversions, conv)
+	}
+	return conversions, nil
+}
+
+// Parse an added column specification of the form name=src:dec|enc.
+func parseAddSpec(spec string) (name, src, dec, enc string, err error) {
+	var rest, steps string
+	var found bool
+	if name, rest, found = strings.Cut(spec, "="); !found || name == "" {
+		return "", "", "", "", fmt.Errorf("invalid column %q: expected name=src:dec|enc", spec)
+	}
+
+	if src, steps, found = strings.Cut(rest, ":"); !found || src == "" {
+		return "", "", "", "", fmt.Errorf("invalid column %q: expected name=src:dec|enc", spec)
+	}
+
+	if dec, enc, found = strings.Cut(steps, "|"); !found || dec == "" || enc == "" {
+		return "", "", "", "", fmt.Errorf("invalid column %q: expected name=src:dec|enc", spec)
+	}
+	return name, src, dec, enc, nil
+}
+
+func columnIndex(name string, header []string) (int, error) {
+	for i, col := range header {
+		if col == name {
+			return i, nil
+		}
+	}
+
+	if idx, err := strconv.Atoi(name); err == nil && idx > 0 {
+		return idx - 1, nil
+	}
+
+	if header == nil {
+		return 0, fmt.Errorf("column %q must be a 1-based position when there is no header", name)
+	}
+	return 0, fmt.Errorf("no column named %q in the header", name)
+}
+
+func parsePolicy(policy string) (string, error) {
+	switch policy = strings.ToLower(strings.TrimSpace(policy)); policy {
+	case failPolicy, skipPolicy, keepPolicy, blankPolicy:
+		return policy, nil
+	case "keep-original", "original":
+		return keepPolicy, nil
+	default:
+		return "", fmt.Errorf("unknown error policy %q, use fail, skip, keep, or blank", policy)
+	}
+}
+
+func parseDelimiter(delimiter string, tsv bool) (rune, error) {
+	if tsv {
+		return '\t', nil
+	}
+
+	switch delimiter {
+	case "", ",":
+		return ',', nil
+	case "tab", `\t`, "\t":
+		return '\t', nil
+	}
+
+	runes := bytes.Runes([]byte(delimiter))
+	if len(runes) != 1 {
+		return 0, fmt.Errorf("invalid delimiter %q", delimiter)
+	}
+	return runes[0], nil
+}
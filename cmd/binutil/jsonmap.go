@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/bbengfort/binutil"
+	"github.com/urfave/cli/v2"
+)
+
+func mapJSON(c *cli.Context) (err error) {
+	if c.NArg() > 1 {
+		return cli.Exit("specify a single file to read or - for stdin", 1)
+	}
+
+	if c.String("decode") == "" || c.String("encode") == "" {
+		return cli.Exit("encoder and decoder must be specified", 1)
+	}
+
+	if err = binutil.CheckPipeline(binutil.Str2StrConversion, c.String("decode"), c.String("encode")); err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	var pipe *binutil.Pipeline
+	if pipe, err = binutil.New(c.String("decode"), c.String("encode")); err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	exprs := c.StringSlice("path")
+	paths := make([]*binutil.JSONPath, 0, len(exprs))
+	for _, expr := range exprs {
+		var path *binutil.JSONPath
+		if path, err = binutil.ParseJSONPath(expr); err != nil {
+			return cli.Exit(err, 1)
+		}
+		paths = append(paths, path)
+	}
+
+	var data []byte
+	if data, err = readInput(c.Args().First()); err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	var (
+		out []byte
+		n   int
+	)
+
+	convert := func(_, val string) (string, error) { return pipe.Str2Str(val) }
+	if out, n, err = binutil.RewriteJSON(data, convert, paths...); err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	if n == 0 && !c.Bool("quiet") {
+		fmt.Fprintln(os.Stderr, "warning: no string values matched the specified paths")
+	}
+
+	if !c.Bool("compact") {
+		buf := &bytes.Buffer{}
+		if err = json.Indent(buf, out, "", "  "); err != nil {
+			return cli.Exit(err, 1)
+		}
+		out = buf.Bytes()
+	}
+
+	fmt.Println(string(out))
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bbengfort/binutil"
+	"github.com/urfave/cli/v2"
+)
+
+func inspectJWT(c *cli.Context) (err error) {
+	if c.NArg() > 1 {
+		return cli.Exit("specify a single token or - for stdin", 1)
+	}
+
+	token := c.Args().First()
+	if token == "" || token == "-" {
+		var data []byte
+		if data, err = readInput(token); err != nil {
+			return cli.Exit(err, 1)
+		}
+		token = string(data)
+	}
+
+	opts := make(binutil.Options)
+	if path := c.String("verify"); path != "" {
+		opts["key"] = "file:" + path
+	}
+
+	if secret := c.String("secret"); secret != "" {
+		opts["secret"] = secret
+	}
+
+	var jwt *binutil.JWT
+	if jwt, err = binutil.NewJWTFromOptions(opts); err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	var enc binutil.Encoder
+	if enc, err = jwt.DecodeString(token); err != nil {
+		return cli.Exit(err, 1)
+	}
+	decoded := enc.(*binutil.JWT).Decoded()
+
+	if c.Bool("json") {
+		var out string
+		if out, err = enc.EncodeString(); err != nil {
+			return cli.Exit(err, 1)
+		}
+		fmt.Println(out)
+		return nil
+	}
+
+	printJSONSection("Header", decoded.Header)
+	if decoded.Encrypted {
+		fmt.Println("Payload is encrypted (JWE) and cannot be decoded")
+		return nil
+	}
+	printJSONSection("Claims", decoded.Claims)
+
+	for _, name := range []string{"iat", "nbf", "exp"} {
+		if ts, ok := decoded.Times[name]; ok {
+			fmt.Printf("%s: %s\n", name, ts)
+		}
+	}
+
+	if decoded.Verified {
+		fmt.Println("Signature: verified")
+	} else {
+		fmt.Println("Signature: not verified (specify --verify or --secret)")
+	}
+	return nil
+}
+
+func printJSONSection(title string, data []byte) {
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		out.Reset()
+		out.Write(data)
+	}
+	fmt.Printf("%s\n%s\n%s\n\n", title, strings.Repeat("=", len(title)), out.String())
+}
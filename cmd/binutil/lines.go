@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/bbengfort/binutil"
+	"github.com/urfave/cli/v2"
+)
+
+// Maximum length of a line that can be read in line mode.
+const maxLineSize = 64 * 1024 * 1024
+
+// lineResult is the conversion of a single line, sent to the writer in input order.
+type lineResult struct {
+	line int
+	out  string
+	err  error
+}
+
+type lineJob struct {
+	line   int
+	in     string
+	result chan<- lineResult
+}
+
+// Convert each line of stdin (or of the file specified by --read) independently using a
+// bounded pool of workers. Results are written in the same order as the input; by
+// default the first error stops the conversion, otherwise errors are written to stderr
+// and an empty line is written in place of the failed conversion so that the output
+// lines correspond to the input lines. Empty lines are passed through.
+func convertLines(c *cli.Context) (err error) {
+	if c.NArg() > 0 {
+		return cli.Exit("cannot specify input arguments with --lines, lines are read from stdin or --read", 1)
+	}
+
+	if c.Bool("trace") {
+		return cli.Exit("cannot trace conversions with --lines", 1)
+	}
+
+	if c.String("decode") == "" || c.String("encode") == "" {
+		return cli.Exit("encoder and decoder must be specified", 1)
+	}
+
+	if err = binutil.CheckPipeline(binutil.Str2StrConversion, c.String("decode"), c.String("encode")); err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	var pipe *binutil.Pipeline
+	if pipe, err = binutil.New(c.String("decode"), c.String("encode")); err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	verify := c.Bool("verify")
+	workers := c.Int("workers")
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+
+	var in io.Reader = os.Stdin
+	if path := c.String("read"); path != "" {
+		var f *os.File
+		if f, err = os.Open(path); err != nil {
+			return cli.Exit(err, 1)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	// The queue holds the result channel of every line in input order; its capacity
+	// bounds the number of lines that are in flight (and buffered awaiting output).
+	jobs := make(chan lineJob, workers)
+	queue := make(chan chan lineResult, workers*4)
+	done := make(chan struct{})
+	defer close(done)
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for job := range jobs {
+				res := lineResult{line: job.line}
+				if job.in != "" {
+					res.out, res.err = convert(pipe, job.in, verify)
+				}
+				job.result <- res
+			}
+		}()
+	}
+
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(queue)
+		defer close(jobs)
+
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+		for line := 1; scanner.Scan(); line++ {
+			result := make(chan lineResult, 1)
+			select {
+			case queue <- result:
+			case <-done:
+				readErr <- nil
+				return
+			}
+			jobs <- lineJob{line: line, in: strings.TrimSuffix(scanner.Text(), "\r"), result: result}
+		}
+		readErr <- scanner.Err()
+	}()
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+
+	total, failed := 0, 0
+	keepGoing := c.Bool("continue")
+	for result := range queue {
+		res := <-result
+		total++
+
+		if res.err != nil {
+			failed++
+			if !keepGoing {
+				out.Flush()
+				return cli.Exit(fmt.Sprintf("line %d: %s", res.line, res.err), 1)
+			}
+			fmt.Fprintf(os.Stderr, "line %d: %s\n", res.line, res.err)
+		}
+
+		out.WriteString(res.out)
+		out.WriteByte('\n')
+	}
+
+	if err = <-readErr; err != nil {
+		out.Flush()
+		return cli.Exit(fmt.Errorf("could not read line %d: %w", total+1, err), 1)
+	}
+
+	if err = out.Flush(); err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	if !c.Bool("quiet") {
+		fmt.Fprintf(os.Stderr, "converted %d of %d lines (%d errors)\n", total-failed, total, failed)
+	}
+
+	if failed > 0 {
+		return cli.Exit("", 1)
+	}
+	return nil
+}
@@ -2,8 +2,11 @@ package main
 
 import (
 	"crypto/rand"
+	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"text/tabwriter"
 	"time"
 
@@ -47,6 +50,34 @@ func main() {
 			Aliases: []string{"b"},
 			Usage:   "the input is binary data not a UTF-8 string",
 		},
+		&cli.BoolFlag{
+			Name:  "trace",
+			Usage: "print the output of every step of the pipeline to stderr",
+		},
+		&cli.BoolFlag{
+			Name:  "verify",
+			Usage: "convert the output back and fail if the input is not reproduced",
+		},
+		&cli.BoolFlag{
+			Name:    "lines",
+			Aliases: []string{"l"},
+			Usage:   "convert each line of stdin (or of --read) independently",
+		},
+		&cli.IntFlag{
+			Name:    "workers",
+			Aliases: []string{"w"},
+			Usage:   "number of workers to convert lines with (defaults to the number of cpus)",
+		},
+		&cli.BoolFlag{
+			Name:    "continue",
+			Aliases: []string{"k"},
+			Usage:   "in line mode, report errors on stderr and continue converting",
+		},
+		&cli.BoolFlag{
+			Name:    "quiet",
+			Aliases: []string{"q"},
+			Usage:   "in line mode, do not print a summary",
+		},
 	}
 	app.Commands = []*cli.Command{
 		{
@@ -54,6 +85,140 @@ func main() {
 			Aliases: []string{"d"},
 			Usage:   "print the list of registered decoders",
 			Action:  listDecoders,
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:    "long",
+					Aliases: []string{"l"},
+					Usage:   "describe the category, capabilities, and aliases of each decoder",
+				},
+				&cli.BoolFlag{
+					Name:    "json",
+					Aliases: []string{"j"},
+					Usage:   "print the decoder descriptions as json",
+				},
+			},
+		},
+		{
+			Name:      "x509",
+			Usage:     "inspect certificates, chains, and certificate requests",
+			ArgsUsage: "[FILE|-]",
+			Action:    inspectX509,
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:    "json",
+					Aliases: []string{"j"},
+					Usage:   "print the certificate information as json",
+				},
+			},
+		},
+		{
+			Name:      "jwt",
+			Usage:     "decode and optionally verify a json web token",
+			ArgsUsage: "[TOKEN|-]",
+			Action:    inspectJWT,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "verify",
+					Usage: "verify the signature with a pem public key or certificate, jwk, or jwks file",
+				},
+				&cli.StringFlag{
+					Name:    "secret",
+					Aliases: []string{"s"},
+					Usage:   "verify an hmac signature with the secret (env:VAR, file:PATH, or literal)",
+				},
+				&cli.BoolFlag{
+					Name:    "json",
+					Aliases: []string{"j"},
+					Usage:   "print the decoded token as json",
+				},
+			},
+		},
+		{
+			Name:      "jsonmap",
+			Usage:     "convert the string values selected by a json path in a json document",
+			ArgsUsage: "[FILE|-]",
+			Action:    mapJSON,
+			Flags: []cli.Flag{
+				&cli.StringSliceFlag{
+					Name:     "path",
+					Aliases:  []string{"p"},
+					Usage:    "a jsonpath or jq-style selector, e.g. $.items[*].id or .items[].id",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:    "decode",
+					Aliases: []string{"d"},
+					Usage:   "the format of the selected values",
+				},
+				&cli.StringFlag{
+					Name:    "encode",
+					Aliases: []string{"e"},
+					Usage:   "the format to convert the selected values to",
+				},
+				&cli.BoolFlag{
+					Name:    "compact",
+					Aliases: []string{"c"},
+					Usage:   "write the document on a single line",
+				},
+				&cli.BoolFlag{
+					Name:    "quiet",
+					Aliases: []string{"q"},
+					Usage:   "do not warn if no values are matched",
+				},
+			},
+		},
+		{
+			Name:      "csv",
+			Usage:     "convert columns of a csv or tsv file",
+			ArgsUsage: "[FILE|-]",
+			Action:    convertCSV,
+			Flags: []cli.Flag{
+				&cli.StringSliceFlag{
+					Name:    "column",
+					Aliases: []string{"c"},
+					Usage:   "a column (name or 1-based position) to convert in place",
+				},
+				&cli.StringFlag{
+					Name:    "decode",
+					Aliases: []string{"d"},
+					Usage:   "the format of the converted columns",
+				},
+				&cli.StringFlag{
+					Name:    "encode",
+					Aliases: []string{"e"},
+					Usage:   "the format to convert the columns to",
+				},
+				&cli.StringSliceFlag{
+					Name:    "add",
+					Aliases: []string{"a"},
+					Usage:   "add a column converted from another column, e.g. id_b64=id:hex|b64",
+				},
+				&cli.StringSliceFlag{
+					Name:  "on-error",
+					Usage: "error policy (fail, skip, keep, or blank) for all columns or for a column with name=policy",
+				},
+				&cli.BoolFlag{
+					Name:    "header",
+					Aliases: []string{"H"},
+					Usage:   "the first row of the file is a header",
+				},
+				&cli.StringFlag{
+					Name:    "delimiter",
+					Aliases: []string{"D"},
+					Usage:   "the field delimiter",
+					Value:   ",",
+				},
+				&cli.BoolFlag{
+					Name:    "tsv",
+					Aliases: []string{"t"},
+					Usage:   "the file is tab delimited",
+				},
+				&cli.BoolFlag{
+					Name:    "quiet",
+					Aliases: []string{"q"},
+					Usage:   "do not report the number of skipped rows",
+				},
+			},
 		},
 		{
 			Name:   "ulid",
@@ -128,6 +293,10 @@ func handler(c *cli.Context) (err error) {
 		return cli.Exit("cannot specify input arguments and a path to read from", 1)
 	}
 
+	if c.Bool("lines") {
+		return convertLines(c)
+	}
+
 	// TODO: handle reading from a file
 	if c.String("read") != "" {
 		return cli.Exit("reading from a file not implemented yet", 3)
@@ -147,6 +316,10 @@ func handler(c *cli.Context) (err error) {
 		return cli.Exit("encoder and decoder must be specified", 1)
 	}
 
+	if err = binutil.CheckPipeline(binutil.Str2StrConversion, c.String("decode"), c.String("encode")); err != nil {
+		return cli.Exit(err, 1)
+	}
+
 	// Handle pipeline of encoders/decoders
 	var pipe *binutil.Pipeline
 	if pipe, err = binutil.New(c.String("decode"), c.String("encode")); err != nil {
@@ -157,7 +330,17 @@ func handler(c *cli.Context) (err error) {
 	for i := 0; i < c.NArg(); i++ {
 		var out string
 		in := args.Get(i)
-		if out, err = pipe.Str2Str(in); err != nil {
+		if c.Bool("trace") {
+			// The traced output is printed and verified so that the input is only
+			// converted once and the trace describes the output that is printed.
+			if out, err = printTrace(pipe, in); err == nil && c.Bool("verify") {
+				err = pipe.Verify(in, out)
+			}
+		} else {
+			out, err = convert(pipe, in, c.Bool("verify"))
+		}
+
+		if err != nil {
 			return cli.Exit(err, 1)
 		}
 		fmt.Println(out)
@@ -165,7 +348,61 @@ func handler(c *cli.Context) (err error) {
 	return nil
 }
 
+// Convert the input with the pipeline, verifying that the conversion can be reversed
+// to reproduce the input if requested.
+func convert(pipe *binutil.Pipeline, in string, verify bool) (string, error) {
+	if verify {
+		return pipe.RoundTrip(in)
+	}
+	return pipe.Str2Str(in)
+}
+
+// Convert the input with the pipeline and print a table of the steps of the conversion
+// to stderr; the error of a failed step is printed in place of its preview. The output
+// and error of the conversion are returned.
+func printTrace(pipe *binutil.Pipeline, in string) (string, error) {
+	result, steps, err := pipe.Trace(in)
+	out := tabwriter.NewWriter(os.Stderr, 4, 4, 2, ' ', 0)
+	fmt.Fprintln(out, "STEP\tDECODER\tIN\tOUT\tDURATION\tOUTPUT")
+	for _, step := range steps {
+		if step.Err != nil {
+			fmt.Fprintf(out, "%d\t%s\t%d\t-\t%s\terror: %s\n", step.Step, step.Name, step.Input, step.Duration, step.Err)
+			continue
+		}
+		fmt.Fprintf(out, "%d\t%s\t%d\t%d\t%s\t%s\n", step.Step, step.Name, step.Input, step.Output, step.Duration, step.Preview)
+	}
+	out.Flush()
+	return result, err
+}
+
 func listDecoders(c *cli.Context) error {
+	if c.Bool("json") {
+		data, err := json.MarshalIndent(binutil.DecoderInfos(), "", "  ")
+		if err != nil {
+			return cli.Exit(err, 1)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if c.Bool("long") {
+		out := tabwriter.NewWriter(os.Stdout, 4, 4, 2, ' ', 0)
+		fmt.Fprintln(out, "NAME\tCATEGORY\tLENGTH\tCAPABILITIES\tALIASES\tDESCRIPTION")
+		for _, info := range binutil.DecoderInfos() {
+			length := "-"
+			if info.Length > 0 {
+				length = strconv.Itoa(info.Length)
+			}
+
+			aliases := "-"
+			if len(info.Aliases) > 0 {
+				aliases = strings.Join(info.Aliases, ",")
+			}
+			fmt.Fprintf(out, "%s\t%s\t%s\t%s\t%s\t%s\n", info.Name, info.Category, length, capabilities(info.Capabilities), aliases, info.Description)
+		}
+		return out.Flush()
+	}
+
 	names := binutil.DecoderNames()
 	fmt.Println("Registered Decoders:\n====================")
 	for _, name := range names {
@@ -273,3 +510,20 @@ func makeRand(c *cli.Context) error {
 	fmt.Print(out)
 	return nil
 }
+
+// Summarize the capabilities of a decoder as the representations it decodes and encodes
+// (b for binary, s for string), e.g. bs>bs for a bidirectional decoder.
+func capabilities(c binutil.Capability) string {
+	flag := func(capability binutil.Capability, r string) string {
+		if c.Has(capability) {
+			return r
+		}
+		return "-"
+	}
+
+	out := flag(binutil.DecodesBinary, "b") + flag(binutil.DecodesString, "s") + ">" + flag(binutil.EncodesBinary, "b") + flag(binutil.EncodesString, "s")
+	if c.Has(binutil.Lossy) {
+		out += " lossy"
+	}
+	return out
+}
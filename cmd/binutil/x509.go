@@ -0,0 +1,373 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bbengfort/binutil"
+	"github.com/urfave/cli/v2"
+)
+
+// Decoders that are attempted in order when the x509 input is not PEM armored.
+var x509Decoders = []string{"hex", "base64", "base64-raw", "base64-url", "base64-rawurl"}
+
+// certificateInfo is the summary of a certificate or certificate request that is
+// printed by the x509 command or serialized as JSON.
+type certificateInfo struct {
+	Type               string                       `json:"type"`
+	Subject            string                       `json:"subject"`
+	Issuer             string                       `json:"issuer,omitempty"`
+	SerialNumber       string                       `json:"serial_number,omitempty"`
+	NotBefore          *time.Time                   `json:"not_before,omitempty"`
+	NotAfter           *time.Time                   `json:"not_after,omitempty"`
+	Status             string                       `json:"status,omitempty"`
+	DNSNames           []string                     `json:"dns_names,omitempty"`
+	IPAddresses        []string                     `json:"ip_addresses,omitempty"`
+	EmailAddresses     []string                     `json:"email_addresses,omitempty"`
+	URIs               []string                     `json:"uris,omitempty"`
+	KeyAlgorithm       string                       `json:"key_algorithm"`
+	SignatureAlgorithm string                       `json:"signature_algorithm"`
+	IsCA               bool                         `json:"is_ca"`
+	Fingerprints       map[string]map[string]string `json:"fingerprints"`
+	Extensions         []extensionInfo              `json:"extensions,omitempty"`
+}
+
+type extensionInfo struct {
+	OID      string `json:"oid"`
+	Name     string `json:"name,omitempty"`
+	Critical bool   `json:"critical"`
+}
+
+func inspectX509(c *cli.Context) (err error) {
+	if c.NArg() > 1 {
+		return cli.Exit("specify a single file to read or - for stdin", 1)
+	}
+
+	var data []byte
+	if data, err = readInput(c.Args().First()); err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	var ders [][]byte
+	if ders, err = decodeX509(data); err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	var multi *binutil.MultiPipeline
+	if multi, err = binutil.NewMulti("hex", "b64"); err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	var infos []*certificateInfo
+	for _, der := range ders {
+		var parsed []*certificateInfo
+		if parsed, err = parseX509(der, multi); err != nil {
+			return cli.Exit(err, 1)
+		}
+		infos = append(infos, parsed...)
+	}
+
+	if c.Bool("json") {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err = encoder.Encode(infos); err != nil {
+			return cli.Exit(err, 1)
+		}
+		return nil
+	}
+
+	for i, info := range infos {
+		if i > 0 {
+			fmt.Println()
+		}
+		printCertificateInfo(info, i+1, len(infos))
+	}
+	return nil
+}
+
+// Read the input data from the specified path or from stdin if the path is empty or -.
+func readInput(path string) ([]byte, error) {
+	if path == "" || path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// Decode the input into one or more DER encoded blobs using the registered decoders:
+// PEM armored input is split into its certificate and certificate request blocks (other
+// blocks such as private keys are skipped with a note on stderr), hex and base64 input
+// is decoded (and checked for PEM armor, e.g. a base64 encoded PEM file from a
+// Kubernetes secret), and any other input is assumed to be DER already.
+func decodeX509(data []byte) (_ [][]byte, err error) {
+	if bytes.Contains(data, []byte("-----BEGIN ")) {
+		var dec binutil.Decoder
+		if dec, err = binutil.NewDecoder("pem"); err != nil {
+			return nil, err
+		}
+
+		var enc binutil.Encoder
+		if enc, err = dec.DecodeString(string(data)); err != nil {
+			return nil, err
+		}
+
+		blocks := enc.(*binutil.PEM).Blocks()
+		ders := make([][]byte, 0, len(blocks))
+		for _, block := range blocks {
+			switch block.Type {
+			case "CERTIFICATE", "CERTIFICATE REQUEST", "NEW CERTIFICATE REQUEST":
+				ders = append(ders, block.Bytes)
+			default:
+				fmt.Fprintf(os.Stderr, "note: skipping %s block\n", block.Type)
+			}
+		}
+
+		if len(ders) == 0 {
+			return nil, errors.New("no certificate or certificate request blocks found in the pem data")
+		}
+		return ders, nil
+	}
+
+	text := strings.Join(strings.Fields(string(data)), "")
+	for _, name := range x509Decoders {
+		var pipe *binutil.Pipeline
+		if pipe, err = binutil.New(name); err != nil {
+			return nil, err
+		}
+
+		var der []byte
+		if der, err = pipe.Str2Bin(text); err == nil && len(der) > 0 {
+			return decodeX509(der)
+		}
+	}
+	return [][]byte{data}, nil
+}
+
+// Parse the DER data as one or more certificates or as a certificate request.
+func parseX509(der []byte, multi *binutil.MultiPipeline) (_ []*certificateInfo, err error) {
+	var certs []*x509.Certificate
+	if certs, err = x509.ParseCertificates(der); err == nil {
+		infos := make([]*certificateInfo, 0, len(certs))
+		for _, cert := range certs {
+			info := certificateSummary(cert)
+			if info.Fingerprints, err = fingerprints(multi, cert.Raw); err != nil {
+				return nil, err
+			}
+			infos = append(infos, info)
+		}
+		return infos, nil
+	}
+
+	var csr *x509.CertificateRequest
+	if csr, err = x509.ParseCertificateRequest(der); err == nil {
+		info := requestSummary(csr)
+		if info.Fingerprints, err = fingerprints(multi, csr.Raw); err != nil {
+			return nil, err
+		}
+		return []*certificateInfo{info}, nil
+	}
+	return nil, errors.New("could not parse input as a certificate, chain, or certificate request")
+}
+
+func certificateSummary(cert *x509.Certificate) *certificateInfo {
+	info := &certificateInfo{
+		Type:               "certificate",
+		Subject:            cert.Subject.String(),
+		Issuer:             cert.Issuer.String(),
+		SerialNumber:       cert.SerialNumber.String(),
+		NotBefore:          &cert.NotBefore,
+		NotAfter:           &cert.NotAfter,
+		DNSNames:           cert.DNSNames,
+		EmailAddresses:     cert.EmailAddresses,
+		KeyAlgorithm:       keyAlgorithm(cert.PublicKey),
+		SignatureAlgorithm: cert.SignatureAlgorithm.String(),
+		IsCA:               cert.IsCA,
+		Extensions:         extensions(cert.Extensions),
+	}
+
+	now := time.Now()
+	switch {
+	case now.Before(cert.NotBefore):
+		info.Status = "not yet valid"
+	case now.After(cert.NotAfter):
+		info.Status = "expired"
+	default:
+		info.Status = fmt.Sprintf("valid (expires in %d days)", int(cert.NotAfter.Sub(now).Hours()/24))
+	}
+
+	for _, ip := range cert.IPAddresses {
+		info.IPAddresses = append(info.IPAddresses, ip.String())
+	}
+
+	for _, uri := range cert.URIs {
+		info.URIs = append(info.URIs, uri.String())
+	}
+	return info
+}
+
+func requestSummary(csr *x509.CertificateRequest) *certificateInfo {
+	info := &certificateInfo{
+		Type:               "certificate request",
+		Subject:            csr.Subject.String(),
+		DNSNames:           csr.DNSNames,
+		EmailAddresses:     csr.EmailAddresses,
+		KeyAlgorithm:       keyAlgorithm(csr.PublicKey),
+		SignatureAlgorithm: csr.SignatureAlgorithm.String(),
+		Extensions:         extensions(csr.Extensions),
+	}
+
+	for _, ip := range csr.IPAddresses {
+		info.IPAddresses = append(info.IPAddresses, ip.String())
+	}
+
+	for _, uri := range csr.URIs {
+		info.URIs = append(info.URIs, uri.String())
+	}
+	return info
+}
+
+func keyAlgorithm(pub any) string {
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		return fmt.Sprintf("RSA %d bits", key.N.BitLen())
+	case *ecdsa.PublicKey:
+		return fmt.Sprintf("ECDSA %s", key.Curve.Params().Name)
+	case ed25519.PublicKey:
+		return "Ed25519"
+	default:
+		return "unknown"
+	}
+}
+
+// Compute the SHA-1 and SHA-256 fingerprints of the DER data in several encodings.
+func fingerprints(multi *binutil.MultiPipeline, der []byte) (_ map[string]map[string]string, err error) {
+	sha1sum := sha1.Sum(der)
+	sha256sum := sha256.Sum256(der)
+
+	out := make(map[string]map[string]string, 2)
+	for alg, sum := range map[string][]byte{"sha1": sha1sum[:], "sha256": sha256sum[:]} {
+		var hexsum, b64sum string
+		if hexsum, err = multi.Bin2Str("hex", sum); err != nil {
+			return nil, err
+		}
+
+		if b64sum, err = multi.Bin2Str("b64", sum); err != nil {
+			return nil, err
+		}
+
+		out[alg] = map[string]string{
+			"hex":    hexsum,
+			"colon":  colonHex(hexsum),
+			"base64": b64sum,
+		}
+	}
+	return out, nil
+}
+
+// Format a hex string in the upper case, colon separated style used by openssl.
+func colonHex(s string) string {
+	s = strings.ToUpper(s)
+	pairs := make([]string, 0, len(s)/2)
+	for i := 0; i+1 < len(s); i += 2 {
+		pairs = append(pairs, s[i:i+2])
+	}
+	return strings.Join(pairs, ":")
+}
+
+func extensions(exts []pkix.Extension) []extensionInfo {
+	out := make([]extensionInfo, 0, len(exts))
+	for _, ext := range exts {
+		oid := ext.Id.String()
+		out = append(out, extensionInfo{OID: oid, Name: binutil.OIDName(oid), Critical: ext.Critical})
+	}
+	return out
+}
+
+func printCertificateInfo(info *certificateInfo, idx, total int) {
+	out := tabwriter.NewWriter(os.Stdout, 4, 4, 2, ' ', 0)
+	title := strings.ToUpper(info.Type[:1]) + info.Type[1:]
+	if total > 1 {
+		title = fmt.Sprintf("%s %d of %d", title, idx, total)
+	}
+	fmt.Fprintf(out, "%s\n%s\n", title, strings.Repeat("=", len(title)))
+
+	fmt.Fprintf(out, "Subject\t%s\n", info.Subject)
+	if info.Issuer != "" {
+		fmt.Fprintf(out, "Issuer\t%s\n", info.Issuer)
+	}
+
+	if info.SerialNumber != "" {
+		fmt.Fprintf(out, "Serial Number\t%s\n", info.SerialNumber)
+	}
+
+	if info.NotBefore != nil && info.NotAfter != nil {
+		fmt.Fprintf(out, "Not Before\t%s\n", info.NotBefore.Format(time.RFC3339))
+		fmt.Fprintf(out, "Not After\t%s\n", info.NotAfter.Format(time.RFC3339))
+		fmt.Fprintf(out, "Status\t%s\n", info.Status)
+	}
+
+	sans := make([]string, 0, len(info.DNSNames)+len(info.IPAddresses)+len(info.EmailAddresses)+len(info.URIs))
+	for _, name := range info.DNSNames {
+		sans = append(sans, "DNS:"+name)
+	}
+
+	for _, name := range info.IPAddresses {
+		sans = append(sans, "IP:"+name)
+	}
+
+	for _, name := range info.EmailAddresses {
+		sans = append(sans, "email:"+name)
+	}
+
+	for _, name := range info.URIs {
+		sans = append(sans, "URI:"+name)
+	}
+
+	if len(sans) > 0 {
+		fmt.Fprintf(out, "SANs\t%s\n", strings.Join(sans, ", "))
+	}
+
+	fmt.Fprintf(out, "Key Algorithm\t%s\n", info.KeyAlgorithm)
+	fmt.Fprintf(out, "Signature Algorithm\t%s\n", info.SignatureAlgorithm)
+	if info.Type == "certificate" {
+		fmt.Fprintf(out, "Is CA\t%t\n", info.IsCA)
+	}
+
+	for _, alg := range []string{"sha1", "sha256"} {
+		name := strings.ToUpper(alg)
+		fmt.Fprintf(out, "%s Fingerprint\t%s\n", name, info.Fingerprints[alg]["colon"])
+		fmt.Fprintf(out, "%s (hex)\t%s\n", name, info.Fingerprints[alg]["hex"])
+		fmt.Fprintf(out, "%s (base64)\t%s\n", name, info.Fingerprints[alg]["base64"])
+	}
+
+	for i, ext := range info.Extensions {
+		label := ""
+		if i == 0 {
+			label = "Extensions"
+		}
+
+		name := ext.OID
+		if ext.Name != "" {
+			name = fmt.Sprintf("%s (%s)", ext.Name, ext.OID)
+		}
+
+		if ext.Critical {
+			name += " [critical]"
+		}
+		fmt.Fprintf(out, "%s\t%s\n", label, name)
+	}
+	out.Flush()
+}
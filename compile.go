@@ -0,0 +1,198 @@
+package binutil
+
+import (
+	"fmt"
+)
+
+// Converter converts the binary representation of one decoder directly into the binary
+// representation of another, appending the result to dst. Because the from decoder is
+// skipped when a fused step decodes binary data, the converter must validate the input
+// as the from decoder would (e.g. that a ULID is 16 bytes).
+type Converter func(dst, src []byte) ([]byte, error)
+
+type converterKey struct {
+	from string
+	to   string
+}
+
+func init() {
+	// The binary representation of these decoders is the raw bytes so converting between
+	// them only requires checking the length of identifiers.
+	raw := map[string]int{
+		HexDecoder:          0,
+		Base64Decoder:       0,
+		StdBase64Decoder:    0,
+		RawBase64Decoder:    0,
+		URLBase64Decoder:    0,
+		RawURLBase64Decoder: 0,
+		ULIDDecoder:         16,
+		UUIDDecoder:         16,
+	}
+
+	for from, fromLength := range raw {
+		for to, toLength := range raw {
+			if from != to && (fromLength == 0 || toLength == 0 || fromLength == toLength) {
+				RegisterConverter(from, to, copyConverter(from, fromLength, to, toLength))
+			}
+		}
+	}
+}
+
+// RegisterConverter registers a converter from the binary representation of the from
+// decoder to the binary representation of the to decoder. Names may be aliases but both
+// decoders must be registered before the converter. Registering a converter for a pair
+// of decoders that already has one replaces it.
+func (r *Registry) RegisterConverter(from, to string, converter Converter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.converters == nil {
+		r.converters = make(map[converterKey]Converter)
+	}
+	r.converters[converterKey{r.canonical(from), r.canonical(to)}] = converter
+}
+
+// Converter returns the converter registered for the pair of decoders, if any.
+func (r *Registry) Converter(from, to string) (Converter, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	converter, ok := r.converters[converterKey{r.canonical(from), r.canonical(to)}]
+	return converter, ok
+}
+
+// Must be called with the lock held.
+func (r *Registry) canonical(name string) string {
+	name = normalizeName(name)
+	if decoder, ok := r.decoders[name]; ok {
+		return decoder.name
+	}
+	return name
+}
+
+// Compile returns a pipeline that performs the same conversion as the pipeline but with
+// adjacent steps fused into a single step wherever a converter is registered for the
+// pair of decoders in the registry the pipeline was created with. A fused step decodes
+// strings with the first decoder and encodes strings with the second, converting the
+// binary data between them with the converter rather than decoding and encoding it
+// again; e.g. ulid to uuid decodes the ULID string and formats the bytes as a UUID.
+// Steps without a converter (or that are Decoder instances) are run step by step. The
+// compiled pipeline can be reused and is safe for concurrent use.
+func (p *Pipeline) Compile() *Pipeline {
+	registry := p.registry
+	if registry == nil {
+		registry = DefaultRegistry
+	}
+
+	out := &Pipeline{
+		steps:    make([]Decoder, 0, len(p.steps)),
+		infos:    make([]*Info, 0, len(p.steps)),
+		limits:   p.limits,
+		registry: p.registry,
+	}
+
+	for i := 0; i < len(p.steps); i++ {
+		if i+1 < len(p.steps) {
+			from, to := p.info(i), p.info(i+1)
+			if from != nil && to != nil {
+				if converter, ok := registry.Converter(from.Name, to.Name); ok {
+					step := &fusedStep{from: p.steps[i], to: p.steps[i+1], fromInfo: from, toInfo: to, convert: converter}
+					out.steps = append(out.steps, step)
+					out.infos = append(out.infos, step.info())
+					i++
+					continue
+				}
+			}
+		}
+
+		out.steps = append(out.steps, p.steps[i])
+		out.infos = append(out.infos, p.info(i))
+	}
+	return out
+}
+
+// fusedStep is two adjacent steps of a compiled pipeline that hand off binary data with
+// a converter. The step decodes the string representation of the from decoder or its
+// binary representation and encodes the representations of the to decoder.
+type fusedStep struct {
+	from     Decoder
+	to       Decoder
+	fromInfo *Info
+	toInfo   *Info
+	convert  Converter
+}
+
+var _ Decoder = &fusedStep{}
+
+// The binary data is validated by the from decoder before it is converted, since the
+// converted data is passed to the to decoder.
+func (f *fusedStep) DecodeBinary(in []byte) (_ Encoder, err error) {
+	if _, err = f.from.DecodeBinary(in); err != nil {
+		return nil, err
+	}
+	return f.decodeConverted(in)
+}
+
+// The string is decoded into a pooled buffer if the from decoder implements
+// AppendDecoder so that only the converted data, which is kept by the Encoder of the to
+// decoder, is allocated.
+func (f *fusedStep) DecodeString(in string) (_ Encoder, err error) {
+	dec, ok := f.from.(AppendDecoder)
+	if !ok {
+		var enc Encoder
+		if enc, err = f.from.DecodeString(in); err != nil {
+			return nil, err
+		}
+
+		var data []byte
+		if data, err = enc.EncodeBinary(); err != nil {
+			return nil, err
+		}
+		return f.decodeConverted(data)
+	}
+
+	src, data := getBuffer(), getBuffer()
+	defer putBuffer(src)
+	defer putBuffer(data)
+
+	*src = append((*src)[:0], in...)
+	if *data, err = dec.AppendDecode((*data)[:0], *src); err != nil {
+		return nil, err
+	}
+	return f.decodeConverted(*data)
+}
+
+// Convert the data and decode it with the to decoder; the converted data is allocated
+// because the Encoder may keep it.
+func (f *fusedStep) decodeConverted(in []byte) (_ Encoder, err error) {
+	var data []byte
+	if data, err = f.convert(make([]byte, 0, len(in)), in); err != nil {
+		return nil, err
+	}
+	return f.to.DecodeBinary(data)
+}
+
+// The fused step decodes like the from step and encodes like the to step; the fixed
+// length of the to step is the length of the converted data.
+func (f *fusedStep) info() *Info {
+	decodes := DecodesBinary | DecodesString
+	encodes := EncodesBinary | EncodesString
+	return &Info{
+		Name:         f.fromInfo.Name + "+" + f.toInfo.Name,
+		Category:     f.toInfo.Category,
+		Length:       f.toInfo.Length,
+		Capabilities: f.fromInfo.Capabilities&decodes | f.toInfo.Capabilities&encodes | (f.fromInfo.Capabilities|f.toInfo.Capabilities)&Lossy,
+	}
+}
+
+// Copy the bytes after checking the fixed lengths of the decoders (if any).
+func copyConverter(from string, fromLength int, to string, toLength int) Converter {
+	return func(dst, src []byte) ([]byte, error) {
+		if fromLength > 0 && len(src) != fromLength {
+			return nil, fmt.Errorf("%s: expected %d bytes, got %d", from, fromLength, len(src))
+		}
+
+		if toLength > 0 && len(src) != toLength {
+			return nil, fmt.Errorf("%s: expected %d bytes, got %d", to, toLength, len(src))
+		}
+		return append(dst, src...), nil
+	}
+}
@@ -0,0 +1,199 @@
+package binutil_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bbengfort/binutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipelineCompile(t *testing.T) {
+	testCases := []struct {
+		steps []string
+		in    string
+		fused []string
+	}{
+		{[]string{"ulid", "uuid"}, "01H3W3MX9A4AFNW55R0MNMQR6Y", []string{"ulid+uuid"}},
+		{[]string{"hex", "b64"}, "deadbeef", []string{"hex+base64"}},
+		{[]string{"uuid", "hex"}, "0188f83a-752a-229f-5e14-b8052b4be0de", []string{"uuid+hex"}},
+		{[]string{"b64", "ulid", "uuid"}, "AYj4OnUqIp9eFLgFK0vg3g==", []string{"base64+ulid", "uuid"}},
+		{[]string{"asn1", "hex", "b64"}, "SEQUENCE\n", []string{"asn1", "hex+base64"}},
+		{[]string{"hex", "ulid"}, "deadbeef", []string{"hex+ulid"}},
+		{[]string{"hex", "ulid"}, "0188f83a752a229f5e14b8052b4be0de", []string{"hex+ulid"}},
+		{[]string{"text", "utf-8"}, "hello", []string{"text", "utf-8"}},
+	}
+
+	for i, tc := range testCases {
+		pipe := mustPipeline(t, tc.steps...)
+		compiled := pipe.Compile()
+
+		// The trace shows the steps of the compiled pipeline if the conversion succeeds
+		_, trace, traceErr := compiled.Trace(tc.in)
+		if traceErr == nil {
+			names := make([]string, 0, len(trace))
+			for _, step := range trace {
+				names = append(names, step.Name)
+			}
+			require.Equal(t, tc.fused, names, "test case %d failed", i)
+		}
+
+		expected, expectedErr := pipe.Str2Str(tc.in)
+		actual, err := compiled.Str2Str(tc.in)
+		require.Equal(t, expectedErr == nil, err == nil, "test case %d failed", i)
+		require.Equal(t, expected, actual, "test case %d failed", i)
+
+		expectedBin, expectedErr := pipe.Str2Bin(tc.in)
+		actualBin, err := compiled.Str2Bin(tc.in)
+		require.Equal(t, expectedErr == nil, err == nil, "test case %d failed", i)
+		require.Equal(t, expectedBin, actualBin, "test case %d failed", i)
+
+		for _, data := range [][]byte{expectedBin, {0xde, 0xad}, {}} {
+			expected, expectedErr = pipe.Bin2Str(data)
+			actual, err = compiled.Bin2Str(data)
+			require.Equal(t, expectedErr == nil, err == nil, "test case %d failed", i)
+			require.Equal(t, expected, actual, "test case %d failed", i)
+
+			expectedBin, expectedErr = pipe.Bin2Bin(data)
+			actualBin, err = compiled.Bin2Bin(data)
+			require.Equal(t, expectedErr == nil, err == nil, "test case %d failed", i)
+			require.Equal(t, expectedBin, actualBin, "test case %d failed", i)
+		}
+	}
+}
+
+func TestCompiledInverse(t *testing.T) {
+	compiled := mustPipeline(t, "ulid", "uuid").Compile()
+
+	inv, err := compiled.Inverse()
+	require.NoError(t, err)
+
+	out, err := inv.Str2Str("0188f83a-752a-229f-5e14-b8052b4be0de")
+	require.NoError(t, err)
+	require.Equal(t, "01H3W3MX9A4AFNW55R0MNMQR6Y", out)
+
+	out, err = compiled.RoundTrip("01H3W3MX9A4AFNW55R0MNMQR6Y")
+	require.NoError(t, err)
+	require.Equal(t, "0188f83a-752a-229f-5e14-b8052b4be0de", out)
+}
+
+func TestRegisterConverter(t *testing.T) {
+	registry := binutil.NewRegistry()
+	registry.Register("hex", func() binutil.Decoder { return &binutil.Hex{} }, "h")
+	registry.Register("b64", func() binutil.Decoder { return binutil.NewBase64(binutil.B64SchemeStd) })
+
+	_, ok := registry.Converter("hex", "b64")
+	require.False(t, ok)
+
+	// A converter that reverses the bytes shows that the converter is used
+	registry.RegisterConverter("H", "b64", func(dst, src []byte) ([]byte, error) {
+		if len(src) == 0 {
+			return nil, errors.New("no data")
+		}
+
+		for i := len(src) - 1; i >= 0; i-- {
+			dst = append(dst, src[i])
+		}
+		return dst, nil
+	})
+
+	_, ok = registry.Converter("hex", "B64")
+	require.True(t, ok, "expected converter to be looked up by canonical name")
+
+	_, ok = registry.Converter("b64", "hex")
+	require.False(t, ok, "expected converters to be directional")
+
+	pipe, err := binutil.New("hex", "b64", binutil.WithRegistry(registry))
+	require.NoError(t, err)
+
+	out, err := pipe.Str2Str("0102")
+	require.NoError(t, err)
+	require.Equal(t, "AQI=", out)
+
+	compiled := pipe.Compile()
+	out, err = compiled.Str2Str("0102")
+	require.NoError(t, err)
+	require.Equal(t, "AgE=", out)
+
+	data, err := compiled.Bin2Bin([]byte{1, 2, 3})
+	require.NoError(t, err)
+	require.Equal(t, []byte{3, 2, 1}, data)
+
+	_, err = compiled.Bin2Str([]byte{})
+	require.EqualError(t, err, "could not decode binary in step 0: no data")
+
+	// The clone of a registry includes its converters
+	_, ok = registry.Clone().Converter("hex", "b64")
+	require.True(t, ok)
+}
+
+func BenchmarkCompiled(b *testing.B) {
+	b.Run("Str2Str/ulid-uuid", func(b *testing.B) {
+		pipe := mustPipeline(b, "ulid", "uuid").Compile()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := pipe.Str2Str("01H3W3MX9A4AFNW55R0MNMQR6Y"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Str2Str/b64-ulid-uuid", func(b *testing.B) {
+		pipe := mustPipeline(b, "b64", "ulid", "uuid").Compile()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := pipe.Str2Str("AYj4OnUqIp9eFLgFK0vg3g=="); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Str2Str/b64-ulid-uuid/limits", func(b *testing.B) {
+		pipe, _ := binutil.New("b64", "ulid", "uuid", binutil.WithLimits(binutil.Limits{MaxInput: 1024}))
+		pipe = pipe.Compile()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := pipe.Str2Str("AYj4OnUqIp9eFLgFK0vg3g=="); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestCompiledValidate(t *testing.T) {
+	registry := binutil.NewRegistry()
+	registry.Register("ulid", func() binutil.Decoder { return &binutil.ULID{} })
+	registry.Describe("ulid", binutil.Info{Category: binutil.IdentifierCategory, Length: 16, Capabilities: binutil.Bidirectional})
+	registry.Register("hex", func() binutil.Decoder { return &binutil.Hex{} })
+	registry.Describe("hex", binutil.Info{Category: binutil.RadixCategory, Capabilities: binutil.Bidirectional})
+	registry.Register("ksuid", func() binutil.Decoder { return &binutil.Hex{} })
+	registry.Describe("ksuid", binutil.Info{Category: binutil.IdentifierCategory, Length: 20, Capabilities: binutil.Bidirectional})
+	registry.RegisterConverter("hex", "ksuid", func(dst, src []byte) ([]byte, error) {
+		if len(src) != 20 {
+			return nil, errors.New("expected 20 bytes")
+		}
+		return append(dst, src...), nil
+	})
+
+	// The fused step has the fixed length of the step it converts to
+	pipe, err := binutil.New("ulid", "hex", "ksuid", binutil.WithRegistry(registry))
+	require.NoError(t, err)
+	require.Empty(t, pipe.Validate())
+
+	diags := pipe.Compile().Validate()
+	require.Len(t, diags, 1)
+	require.EqualError(t, diags[0], "step 1 (hex+ksuid): expects 20 bytes but step 0 (ulid) produces 16 bytes")
+
+	require.Empty(t, mustPipeline(t, "b64", "ulid", "uuid").Compile().Validate())
+}
+
+func TestCompiledDecodeBinary(t *testing.T) {
+	// Binary data is validated by the first decoder of a fused step
+	compiled := mustPipeline(t, "ulid", "uuid").Compile()
+	_, err := compiled.Bin2Str([]byte{1, 2, 3})
+	require.Error(t, err)
+
+	out, err := compiled.Bin2Str([]byte{0x01, 0x88, 0xf8, 0x3a, 0x75, 0x2a, 0x22, 0x9f, 0x5e, 0x14, 0xb8, 0x05, 0x2b, 0x4b, 0xe0, 0xde})
+	require.NoError(t, err)
+	require.Equal(t, "0188f83a-752a-229f-5e14-b8052b4be0de", out)
+}
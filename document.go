@@ -0,0 +1,109 @@
+package binutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// Structured binary formats (e.g. MessagePack and CBOR) are bridged to JSON with the
+// ordered JSON parser and writer of the structured formats. The helpers in this file
+// convert between ordered documents and the generic Go values of the binary formats
+// without losing integer precision.
+
+// Parse a single JSON document into generic values, converting numbers into int64,
+// uint64, or float64 values so that they are encoded as the most appropriate type.
+func parseJSON(data []byte) (v any, err error) {
+	if v, err = parseOrderedJSON(data); err != nil {
+		return nil, err
+	}
+	return plainValue(v), nil
+}
+
+// Convert generic values decoded from a binary format into an ordered document: maps
+// with non-string keys have their keys formatted as strings, CBOR tags become objects
+// with the tag number and value, and non-finite floats become strings.
+func toJSON(v any) any {
+	switch t := v.(type) {
+	case map[any]any:
+		out := newOrderedMap()
+		for key, val := range t {
+			switch k := key.(type) {
+			case string:
+				out.set(k, toJSON(val))
+			case []byte:
+				out.set(fmt.Sprintf("%x", k), toJSON(val))
+			default:
+				out.set(fmt.Sprint(k), toJSON(val))
+			}
+		}
+		return out
+	case map[string]any:
+		out := newOrderedMap()
+		for key, val := range t {
+			out.set(key, toJSON(val))
+		}
+		return out
+	case []any:
+		out := make([]any, len(t))
+		for i, val := range t {
+			out[i] = toJSON(val)
+		}
+		return out
+	case cbor.Tag:
+		out := newOrderedMap()
+		out.set("tag", t.Number)
+		out.set("value", toJSON(t.Content))
+		return out
+	case int8:
+		return int64(t)
+	case int16:
+		return int64(t)
+	case int32:
+		return int64(t)
+	case uint8:
+		return uint64(t)
+	case uint16:
+		return uint64(t)
+	case uint32:
+		return uint64(t)
+	case cbor.SimpleValue:
+		return uint64(t)
+	case big.Int:
+		return json.Number(t.String())
+	case *big.Int:
+		return json.Number(t.String())
+	case float32:
+		return toJSON(float64(t))
+	case float64:
+		if math.IsNaN(t) || math.IsInf(t, 0) {
+			return strconv.FormatFloat(t, 'g', -1, 64)
+		}
+		return t
+	default:
+		return v
+	}
+}
+
+// Marshal a generic value as JSON with sorted keys, optionally indented for readability.
+func marshalJSON(v any, pretty bool) (_ []byte, err error) {
+	buf := &bytes.Buffer{}
+	if err = (jsonWriter{sorted: true}).write(buf, toJSON(v)); err != nil {
+		return nil, err
+	}
+
+	if !pretty {
+		return buf.Bytes(), nil
+	}
+
+	out := &bytes.Buffer{}
+	if err = json.Indent(out, buf.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
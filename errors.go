@@ -3,9 +3,32 @@ package binutil
 import "errors"
 
 var (
-	ErrEmptyPipeline    = errors.New("the pipeline has no transformation steps")
-	ErrOverwrite        = errors.New("this operation will overwrite existing data")
-	ErrNoData           = errors.New("data cannot be empty or nil")
-	ErrUnknownB64Scheme = errors.New("unknown base64 encoding scheme")
-	ErrUnknownStepType  = errors.New("initialize a pipeline with a string or Decoder")
+	ErrEmptyPipeline         = errors.New("the pipeline has no transformation steps")
+	ErrOverwrite             = errors.New("this operation will overwrite existing data")
+	ErrNoData                = errors.New("data cannot be empty or nil")
+	ErrUnknownB64Scheme      = errors.New("unknown base64 encoding scheme")
+	ErrUnknownStepType       = errors.New("initialize a pipeline with a string or Decoder")
+	ErrNoKey                 = errors.New("a key is required, specify it with key=env:VAR or key=file:PATH")
+	ErrUnknownCipher         = errors.New("unknown aead cipher")
+	ErrUnknownNonceLayout    = errors.New("unknown nonce layout, use prefix, suffix, or separate")
+	ErrInvalidKey            = errors.New("the key is not a valid size for the cipher")
+	ErrFixedNonce            = errors.New("the iv option can only be used with the separate nonce layout")
+	ErrInvalidNonce          = errors.New("nonce is missing or has the wrong size for the cipher")
+	ErrCiphertextTooShort    = errors.New("ciphertext is too short to contain a nonce and tag")
+	ErrNoPEMBlocks           = errors.New("no pem blocks matched the type and index filters")
+	ErrInvalidToken          = errors.New("invalid compact serialization of a jwt")
+	ErrEncryptedToken        = errors.New("the jwe payload is encrypted and cannot be decoded or verified")
+	ErrSignatureInvalid      = errors.New("the jwt signature could not be verified")
+	ErrInvalidPipeline       = errors.New("the steps of the pipeline are not compatible")
+	ErrUnsupportedConversion = errors.New("the decoder does not support the conversion")
+	ErrNoDescriptor          = errors.New("a descriptor set and message type are required, e.g. protobuf:desc=api.binpb,type=pkg.Msg")
+	ErrIrreversible          = errors.New("the pipeline cannot be inverted")
+	ErrRoundTrip             = errors.New("the round trip did not reproduce the input")
+	ErrInputTooLarge         = errors.New("the input exceeds the maximum input size of the pipeline")
+	ErrOutputTooLarge        = errors.New("the output of a step exceeds the maximum output size of the pipeline")
+	ErrExpansionTooLarge     = errors.New("the output of a step exceeds the maximum expansion ratio of the pipeline")
+	ErrTimeout               = errors.New("the conversion exceeded the timeout of the pipeline")
+	ErrTrailingData          = errors.New("the data contains trailing bytes after the value")
+	ErrDuplicateOutput       = errors.New("the label of each output of a multi pipeline must be unique")
+	ErrNoOutputLabel         = errors.New("the outputs of a multi pipeline must have a label")
 )
@@ -0,0 +1,154 @@
+package binutil
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Format selects the decoder used to represent a Field as text. Custom formats can be
+// defined for any registered decoder, including decoders with options, e.g.
+//
+//	type AsPostgres struct{}
+//	func (AsPostgres) Step() string { return "sql-postgres" }
+type Format interface {
+	Step() string
+}
+
+// Formats for the built-in binary to text decoders.
+type (
+	AsHex          struct{}
+	AsBase64       struct{}
+	AsBase64Raw    struct{}
+	AsBase64URL    struct{}
+	AsBase64RawURL struct{}
+	AsULID         struct{}
+	AsUUID         struct{}
+)
+
+func (AsHex) Step() string          { return HexDecoder }
+func (AsBase64) Step() string       { return Base64Decoder }
+func (AsBase64Raw) Step() string    { return RawBase64Decoder }
+func (AsBase64URL) Step() string    { return URLBase64Decoder }
+func (AsBase64RawURL) Step() string { return RawURLBase64Decoder }
+func (AsULID) Step() string         { return ULIDDecoder }
+func (AsUUID) Step() string         { return UUIDDecoder }
+
+// Field is binary data that is marshaled as text using the decoder selected by its
+// format, so that struct fields can be serialized to JSON, YAML, or TOML as e.g. hex or
+// URL-safe base64 without custom marshaling methods:
+//
+//	type Record struct {
+//	    ID        binutil.Field[binutil.AsULID]         `json:"id"`
+//	    Signature binutil.Field[binutil.AsBase64URL]    `json:"signature,omitempty"`
+//	}
+//
+// Field implements encoding.TextMarshaler and encoding.TextUnmarshaler, which are used
+// by the JSON, YAML, and TOML libraries. A nil Field is marshaled as null in JSON and
+// null unmarshals into a nil Field; an empty (non-nil) Field is marshaled as an empty
+// string.
+type Field[F Format] []byte
+
+var (
+	_ encoding.TextMarshaler   = Field[AsHex]{}
+	_ encoding.TextUnmarshaler = &Field[AsHex]{}
+	_ json.Marshaler           = Field[AsHex]{}
+	_ json.Unmarshaler         = &Field[AsHex]{}
+)
+
+// MarshalText encodes the field as a string using the format's decoder.
+func (f Field[F]) MarshalText() (_ []byte, err error) {
+	if len(f) == 0 {
+		return []byte{}, nil
+	}
+
+	var pipe *Pipeline
+	if pipe, err = f.pipeline(); err != nil {
+		return nil, err
+	}
+
+	var out string
+	if out, err = pipe.Bin2Str(f); err != nil {
+		return nil, err
+	}
+	return []byte(out), nil
+}
+
+// UnmarshalText decodes the field from a string using the format's decoder.
+func (f *Field[F]) UnmarshalText(text []byte) (err error) {
+	if len(text) == 0 {
+		*f = Field[F]{}
+		return nil
+	}
+
+	var pipe *Pipeline
+	if pipe, err = f.pipeline(); err != nil {
+		return err
+	}
+
+	var data []byte
+	if data, err = pipe.Str2Bin(string(text)); err != nil {
+		return err
+	}
+	*f = append(Field[F]{}, data...)
+	return nil
+}
+
+// MarshalJSON marshals the field as a JSON string or as null if the field is nil.
+func (f Field[F]) MarshalJSON() (_ []byte, err error) {
+	if f == nil {
+		return []byte("null"), nil
+	}
+
+	var text []byte
+	if text, err = f.MarshalText(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON unmarshals the field from a JSON string; null sets the field to nil.
+func (f *Field[F]) UnmarshalJSON(data []byte) (err error) {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		*f = nil
+		return nil
+	}
+
+	var text string
+	if err = json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	return f.UnmarshalText([]byte(text))
+}
+
+// String returns the text representation of the field.
+func (f Field[F]) String() string {
+	text, err := f.MarshalText()
+	if err != nil {
+		return fmt.Sprintf("%x", []byte(f))
+	}
+	return string(text)
+}
+
+// The pipelines of the field formats are created from the DefaultRegistry the first time
+// a field of the format is marshaled or unmarshaled and cached by the format type.
+var fieldPipelines sync.Map
+
+func (Field[F]) pipeline() (*Pipeline, error) {
+	key := reflect.TypeOf((*F)(nil)).Elem()
+	if pipe, ok := fieldPipelines.Load(key); ok {
+		return pipe.(*Pipeline), nil
+	}
+
+	var format F
+	pipe, err := New(format.Step())
+	if err != nil {
+		return nil, fmt.Errorf("invalid field format %T: %w", format, err)
+	}
+
+	cached, _ := fieldPipelines.LoadOrStore(key, pipe)
+	return cached.(*Pipeline), nil
+}
@@ -0,0 +1,117 @@
+package binutil_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/bbengfort/binutil"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+type fieldRecord struct {
+	ID        binutil.Field[binutil.AsULID]      `json:"id" yaml:"id" toml:"id"`
+	Key       binutil.Field[binutil.AsHex]       `json:"key" yaml:"key" toml:"key"`
+	Signature binutil.Field[binutil.AsBase64URL] `json:"signature" yaml:"signature" toml:"signature"`
+}
+
+var fieldFixture = fieldRecord{
+	ID:        binutil.Field[binutil.AsULID]{0x01, 0x88, 0xf8, 0x3a, 0x75, 0x2a, 0x22, 0x9f, 0x5e, 0x14, 0xb8, 0x05, 0x2b, 0x4b, 0xe0, 0xde},
+	Key:       binutil.Field[binutil.AsHex]{0xde, 0xad, 0xbe, 0xef},
+	Signature: binutil.Field[binutil.AsBase64URL]{0xfb, 0xff, 0xfe},
+}
+
+func TestFieldJSON(t *testing.T) {
+	data, err := json.Marshal(fieldFixture)
+	require.NoError(t, err, "could not marshal fields")
+	require.Equal(t, `{"id":"01H3W3MX9A4AFNW55R0MNMQR6Y","key":"deadbeef","signature":"-__-"}`, string(data))
+
+	out := fieldRecord{}
+	require.NoError(t, json.Unmarshal(data, &out), "could not unmarshal fields")
+	require.Equal(t, fieldFixture, out)
+
+	// Nil fields are null and null unmarshals into a nil field
+	data, err = json.Marshal(fieldRecord{Key: binutil.Field[binutil.AsHex]{}})
+	require.NoError(t, err, "could not marshal empty fields")
+	require.Equal(t, `{"id":null,"key":"","signature":null}`, string(data))
+
+	out = fieldRecord{}
+	require.NoError(t, json.Unmarshal([]byte(`{"id":null,"key":"","signature":null}`), &out))
+	require.Nil(t, out.ID)
+	require.Nil(t, out.Signature)
+	require.Empty(t, out.Key)
+
+	err = json.Unmarshal([]byte(`{"key":"not hex"}`), &out)
+	require.Error(t, err, "expected invalid hex to be rejected")
+
+	err = json.Unmarshal([]byte(`{"key":42}`), &out)
+	require.Error(t, err, "expected non-string value to be rejected")
+}
+
+func TestFieldYAMLAndTOML(t *testing.T) {
+	data, err := yaml.Marshal(fieldFixture)
+	require.NoError(t, err, "could not marshal fields as yaml")
+	require.Equal(t, "id: 01H3W3MX9A4AFNW55R0MNMQR6Y\nkey: deadbeef\nsignature: -__-\n", string(data))
+
+	out := fieldRecord{}
+	require.NoError(t, yaml.Unmarshal(data, &out), "could not unmarshal yaml fields")
+	require.Equal(t, fieldFixture, out)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, toml.NewEncoder(buf).Encode(fieldFixture), "could not marshal fields as toml")
+	require.Equal(t, "id = \"01H3W3MX9A4AFNW55R0MNMQR6Y\"\nkey = \"deadbeef\"\nsignature = \"-__-\"\n", buf.String())
+
+	out = fieldRecord{}
+	_, err = toml.Decode(buf.String(), &out)
+	require.NoError(t, err, "could not unmarshal toml fields")
+	require.Equal(t, fieldFixture, out)
+}
+
+type asPostgres struct{}
+
+func (asPostgres) Step() string { return "sql-postgres:as=bytea" }
+
+func TestFieldCustomFormat(t *testing.T) {
+	f := binutil.Field[asPostgres]{0x01, 0x02}
+	require.Equal(t, `'\x0102'::bytea`, f.String())
+
+	text, err := f.MarshalText()
+	require.NoError(t, err)
+
+	out := binutil.Field[asPostgres]{}
+	require.NoError(t, out.UnmarshalText(text))
+	require.Equal(t, f, out)
+}
+
+func TestFieldConcurrent(t *testing.T) {
+	// The cached format pipelines are shared by every field of the format
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			f := binutil.Field[binutil.AsHex]{byte(i), 0xff}
+
+			text, err := f.MarshalText()
+			require.NoError(t, err)
+
+			out := binutil.Field[binutil.AsHex]{}
+			require.NoError(t, out.UnmarshalText(text))
+			require.Equal(t, f, out)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func BenchmarkFieldMarshalText(b *testing.B) {
+	f := fieldFixture.ID
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := f.MarshalText(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
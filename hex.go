@@ -4,6 +4,7 @@ import "encoding/hex"
 
 func init() {
 	RegisterDecoder(HexDecoder, func() Decoder { return &Hex{} }, "hexadecimal")
+	DescribeDecoder(HexDecoder, Info{Description: "hexadecimal encoding", Category: RadixCategory, Capabilities: Bidirectional})
 }
 
 const HexDecoder = "hex"
@@ -13,8 +14,10 @@ type Hex struct {
 }
 
 var (
-	_ Encoder = &Hex{}
-	_ Decoder = &Hex{}
+	_ Encoder       = &Hex{}
+	_ Decoder       = &Hex{}
+	_ AppendEncoder = &Hex{}
+	_ AppendDecoder = &Hex{}
 )
 
 func (h Hex) DecodeBinary(in []byte) (Encoder, error) {
@@ -42,3 +45,26 @@ func (h Hex) EncodeString() (string, error) {
 	}
 	return hex.EncodeToString(h.data), nil
 }
+
+// AppendEncode appends the hex encoding of src to dst.
+func (h Hex) AppendEncode(dst, src []byte) ([]byte, error) {
+	var tail []byte
+	dst, tail = grow(dst, hex.EncodedLen(len(src)))
+	hex.Encode(tail, src)
+	return dst, nil
+}
+
+// AppendDecode appends the bytes represented by the hex string src to dst.
+func (h Hex) AppendDecode(dst, src []byte) (_ []byte, err error) {
+	var (
+		tail []byte
+		n    int
+	)
+
+	size := len(dst)
+	dst, tail = grow(dst, hex.DecodedLen(len(src)))
+	if n, err = hex.Decode(tail, src); err != nil {
+		return dst[:size], err
+	}
+	return dst[:size+n], nil
+}
@@ -0,0 +1,246 @@
+package binutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Categories group decoders by the kind of conversion they perform.
+const (
+	UnknownCategory Category = iota
+	TextCategory
+	RadixCategory
+	IdentifierCategory
+	CompressionCategory
+	CryptoCategory
+	StructuredCategory
+)
+
+// Capabilities describe which representations a decoder can convert from and to.
+const (
+	DecodesBinary Capability = 1 << iota
+	DecodesString
+	EncodesBinary
+	EncodesString
+
+	// Lossy decoders do not reproduce their input when the decoded value is encoded
+	// again, e.g. characters that cannot be represented in a charset are replaced, data
+	// is re-encrypted with a new nonce, or the string representation is a summary that
+	// cannot be decoded.
+	Lossy
+
+	// Bidirectional decoders convert both representations in both directions.
+	Bidirectional = DecodesBinary | DecodesString | EncodesBinary | EncodesString
+)
+
+// Conversions describe the representations of the input and output of a pipeline and
+// correspond to the Pipeline methods.
+const (
+	Bin2BinConversion Conversion = iota
+	Bin2StrConversion
+	Str2BinConversion
+	Str2StrConversion
+)
+
+// Info describes a registered decoder. The name and aliases are populated from the
+// registry; the rest of the information is provided by Describe.
+type Info struct {
+	Name         string     `json:"name"`
+	Aliases      []string   `json:"aliases,omitempty"`
+	Description  string     `json:"description,omitempty"`
+	Category     Category   `json:"category"`
+	Length       int        `json:"length,omitempty"`
+	Capabilities Capability `json:"capabilities"`
+	Options      []string   `json:"options,omitempty"`
+}
+
+// Describe attaches information to the decoder registered with the name (or alias).
+// Decoders that have not been described are bidirectional with an unknown category.
+func (r *Registry) Describe(name string, info Info) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.infos == nil {
+		r.infos = make(map[string]Info)
+	}
+
+	name = normalizeName(name)
+	if decoder, ok := r.decoders[name]; ok {
+		name = decoder.name
+	}
+
+	info.Name, info.Aliases = "", nil
+	r.infos[name] = info
+}
+
+// Info returns the information about the decoder registered with the name or alias.
+func (r *Registry) Info(name string) (_ Info, ok bool) {
+	var (
+		decoder   decoder
+		info      Info
+		described bool
+	)
+
+	r.mu.RLock()
+	if decoder, ok = r.decoders[normalizeName(name)]; ok {
+		info, described = r.infos[decoder.name]
+	}
+	r.mu.RUnlock()
+
+	if !ok {
+		return Info{}, false
+	}
+
+	if !described {
+		info = Info{Capabilities: Bidirectional}
+	}
+
+	info.Name = decoder.name
+	info.Aliases = r.Aliases(decoder.name)
+	return info, true
+}
+
+// Infos returns the information about every registered decoder sorted by name.
+func (r *Registry) Infos() []Info {
+	names := r.Names()
+	out := make([]Info, 0, len(names))
+	for _, name := range names {
+		if info, ok := r.Info(name); ok {
+			out = append(out, info)
+		}
+	}
+	return out
+}
+
+// Check returns an error if one of the steps cannot perform the conversion it would be
+// required to perform in the pipeline; e.g. the first step of a Str2Str pipeline must
+// decode strings and intermediate steps must decode and encode binary data. Steps are
+// decoder specifications and unknown decoders are an error, so a pipeline can be
+// checked before any data is read.
+func (r *Registry) Check(conv Conversion, steps ...string) (err error) {
+	if len(steps) == 0 {
+		return ErrEmptyPipeline
+	}
+
+	last := len(steps) - 1
+	for i, step := range steps {
+		name, _, _ := strings.Cut(step, ":")
+		info, ok := r.Info(name)
+		if !ok {
+			return fmt.Errorf("no registered decoder with the name %q", normalizeName(name))
+		}
+
+		decodes, encodes := DecodesBinary, EncodesBinary
+		if i == 0 && (conv == Str2BinConversion || conv == Str2StrConversion) {
+			decodes = DecodesString
+		}
+		if i == last && (conv == Bin2StrConversion || conv == Str2StrConversion) {
+			encodes = EncodesString
+		}
+
+		for _, capability := range []Capability{decodes, encodes} {
+			if !info.Capabilities.Has(capability) {
+				return fmt.Errorf("%w: %s cannot %s in step %d", ErrUnsupportedConversion, info.Name, strings.Replace(capability.String(), "-", " ", 1), i)
+			}
+		}
+	}
+	return nil
+}
+
+// CheckPipeline checks the steps of a pipeline against the DefaultRegistry.
+func CheckPipeline(conv Conversion, steps ...string) error {
+	return DefaultRegistry.Check(conv, steps...)
+}
+
+// DescribeDecoder attaches information to a decoder in the DefaultRegistry.
+func DescribeDecoder(name string, info Info) {
+	DefaultRegistry.Describe(name, info)
+}
+
+// DecoderInfo returns the information about a decoder in the DefaultRegistry; the name
+// may be a step specification with options.
+func DecoderInfo(name string) (Info, bool) {
+	name, _, _ = strings.Cut(name, ":")
+	return DefaultRegistry.Info(name)
+}
+
+// DecoderInfos returns the information about every decoder in the DefaultRegistry.
+func DecoderInfos() []Info {
+	return DefaultRegistry.Infos()
+}
+
+type Category uint8
+
+func (c Category) String() string {
+	switch c {
+	case TextCategory:
+		return "text"
+	case RadixCategory:
+		return "radix"
+	case IdentifierCategory:
+		return "identifier"
+	case CompressionCategory:
+		return "compression"
+	case CryptoCategory:
+		return "crypto"
+	case StructuredCategory:
+		return "structured"
+	default:
+		return "unknown"
+	}
+}
+
+func (c Category) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+type Conversion uint8
+
+func (c Conversion) String() string {
+	switch c {
+	case Bin2BinConversion:
+		return "bin2bin"
+	case Bin2StrConversion:
+		return "bin2str"
+	case Str2BinConversion:
+		return "str2bin"
+	case Str2StrConversion:
+		return "str2str"
+	default:
+		return "unknown"
+	}
+}
+
+type Capability uint8
+
+// Has returns true if all of the specified capabilities are set.
+func (c Capability) Has(capability Capability) bool {
+	return c&capability == capability
+}
+
+func (c Capability) String() string {
+	return strings.Join(c.names(), ",")
+}
+
+func (c Capability) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.names())
+}
+
+func (c Capability) names() []string {
+	names := make([]string, 0, 5)
+	for _, flag := range []struct {
+		capability Capability
+		name       string
+	}{
+		{DecodesBinary, "decode-binary"},
+		{DecodesString, "decode-string"},
+		{EncodesBinary, "encode-binary"},
+		{EncodesString, "encode-string"},
+		{Lossy, "lossy"},
+	} {
+		if c.Has(flag.capability) {
+			names = append(names, flag.name)
+		}
+	}
+	return names
+}
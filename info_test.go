@@ -0,0 +1,120 @@
+package binutil_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bbengfort/binutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoderInfo(t *testing.T) {
+	info, ok := binutil.DecoderInfo("uuid4")
+	require.True(t, ok, "expected info to be looked up by alias")
+	require.Equal(t, binutil.UUIDDecoder, info.Name)
+	require.Equal(t, []string{"uuid4", "uuid5"}, info.Aliases)
+	require.Equal(t, binutil.IdentifierCategory, info.Category)
+	require.Equal(t, 16, info.Length)
+	require.Equal(t, binutil.Bidirectional, info.Capabilities)
+
+	info, ok = binutil.DecoderInfo("pem:type=CERTIFICATE")
+	require.True(t, ok, "expected options to be stripped from the specification")
+	require.Equal(t, binutil.PEMDecoder, info.Name)
+	require.Equal(t, []string{"type", "index"}, info.Options)
+
+	info, ok = binutil.DecoderInfo("ascii")
+	require.True(t, ok)
+	require.True(t, info.Capabilities.Has(binutil.Lossy))
+	require.True(t, info.Capabilities.Has(binutil.Bidirectional))
+
+	// Tokens cannot be created from the decoded claims so jwt pipelines cannot be
+	// inverted, but a wrapped token can be decoded
+	info, ok = binutil.DecoderInfo("jwt")
+	require.True(t, ok)
+	require.True(t, info.Capabilities.Has(binutil.Lossy))
+
+	pipe, err := binutil.New("jwt", "hex")
+	require.NoError(t, err)
+
+	_, err = pipe.Inverse()
+	require.ErrorIs(t, err, binutil.ErrIrreversible)
+
+	_, err = binutil.New("b64", "jwt")
+	require.NoError(t, err)
+
+	// The relaxed Extended JSON of bson documents loses numeric types
+	info, ok = binutil.DecoderInfo("bson:relaxed")
+	require.True(t, ok)
+	require.True(t, info.Capabilities.Has(binutil.Lossy))
+
+	_, ok = binutil.DecoderInfo("foo")
+	require.False(t, ok)
+
+	// Every registered decoder should be described
+	infos := binutil.DecoderInfos()
+	require.Len(t, infos, len(binutil.DecoderNames()))
+	for _, info := range infos {
+		require.NotEqual(t, binutil.UnknownCategory, info.Category, "%s has no category", info.Name)
+		require.NotEmpty(t, info.Description, "%s has no description", info.Name)
+	}
+
+	data, err := json.Marshal(infos[0])
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name":"aesgcm","aliases":["aes-gcm"],"description":"AES-GCM authenticated encryption of the plaintext string","category":"crypto","capabilities":["decode-binary","decode-string","encode-binary","encode-string","lossy"],"options":["key","nonce","iv","aad"]}`, string(data))
+}
+
+func TestRegistryDescribe(t *testing.T) {
+	registry := binutil.NewRegistry()
+	registry.Register("hex", func() binutil.Decoder { return &binutil.Hex{} }, "h")
+	registry.Register("digest", func() binutil.Decoder { return &binutil.Hex{} })
+
+	// Undescribed decoders are bidirectional with an unknown category
+	info, ok := registry.Info("h")
+	require.True(t, ok)
+	require.Equal(t, binutil.Info{Name: "hex", Aliases: []string{"h"}, Capabilities: binutil.Bidirectional}, info)
+
+	// Decoders can be described by alias; the name and aliases come from the registry
+	registry.Describe("H", binutil.Info{Name: "foo", Description: "hexadecimal", Category: binutil.RadixCategory, Capabilities: binutil.Bidirectional})
+	info, ok = registry.Info("hex")
+	require.True(t, ok)
+	require.Equal(t, "hex", info.Name)
+	require.Equal(t, "hexadecimal", info.Description)
+	require.Equal(t, binutil.RadixCategory, info.Category)
+
+	// A decoder that can only convert strings to a binary digest
+	registry.Describe("digest", binutil.Info{Category: binutil.CryptoCategory, Capabilities: binutil.DecodesString | binutil.EncodesBinary})
+	require.Len(t, registry.Infos(), 2)
+
+	clone := registry.Clone()
+	info, ok = clone.Info("digest")
+	require.True(t, ok)
+	require.Equal(t, binutil.CryptoCategory, info.Category)
+
+	testCases := []struct {
+		conv  binutil.Conversion
+		steps []string
+		err   string
+	}{
+		{binutil.Str2StrConversion, []string{"hex", "h"}, ""},
+		{binutil.Str2BinConversion, []string{"digest"}, ""},
+		{binutil.Str2StrConversion, []string{"digest", "hex"}, ""},
+		{binutil.Str2StrConversion, []string{"digest"}, "the decoder does not support the conversion: digest cannot encode string in step 0"},
+		{binutil.Bin2BinConversion, []string{"digest"}, "the decoder does not support the conversion: digest cannot decode binary in step 0"},
+		{binutil.Str2StrConversion, []string{"hex", "digest"}, "the decoder does not support the conversion: digest cannot decode binary in step 1"},
+		{binutil.Str2StrConversion, []string{"hex", "b64"}, `no registered decoder with the name "b64"`},
+		{binutil.Str2StrConversion, nil, binutil.ErrEmptyPipeline.Error()},
+	}
+
+	for i, tc := range testCases {
+		err := registry.Check(tc.conv, tc.steps...)
+		if tc.err == "" {
+			require.NoError(t, err, "test case %d failed", i)
+		} else {
+			require.EqualError(t, err, tc.err, "test case %d failed", i)
+		}
+	}
+
+	err := registry.Check(binutil.Bin2StrConversion, "digest")
+	require.ErrorIs(t, err, binutil.ErrUnsupportedConversion)
+	require.NoError(t, binutil.CheckPipeline(binutil.Str2StrConversion, "ulid", "uuid"))
+}
@@ -0,0 +1,98 @@
+package binutil
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Inverse returns a pipeline that performs the reverse conversion of the pipeline, e.g.
+// the inverse of New("hex", "b64") converts base64 strings back into hex strings. Every
+// step must be reversible: lossy steps and steps that cannot decode and encode both
+// representations cause ErrIrreversible to be returned. Steps that were not described
+// (e.g. Decoder instances) are assumed to be reversible.
+func (p *Pipeline) Inverse() (_ *Pipeline, err error) {
+	if len(p.steps) == 0 {
+		return nil, ErrEmptyPipeline
+	}
+
+	for i := range p.steps {
+		if info := p.info(i); info != nil {
+			if info.Capabilities.Has(Lossy) {
+				return nil, fmt.Errorf("%w: step %d (%s) is lossy", ErrIrreversible, i, info.Name)
+			}
+
+			if !info.Capabilities.Has(Bidirectional) {
+				return nil, fmt.Errorf("%w: step %d (%s) is not bidirectional", ErrIrreversible, i, info.Name)
+			}
+		}
+	}
+	return p.reverse(), nil
+}
+
+// RoundTrip converts the input string with the pipeline then converts the output back
+// with the reverse of the pipeline and returns an error wrapping ErrRoundTrip if the
+// original input is not reproduced. Unlike Inverse, lossy steps are allowed so that the
+// round trip can verify that a lossy step has not corrupted the input (e.g. a charset
+// that cannot represent a character). If the strings differ but the first step is not
+// lossy, the round trip succeeds if both strings decode to the same bytes so that
+// formatting differences such as the case of hex digits are ignored. The output of the
+// pipeline is returned so that it can be used if the round trip succeeds.
+func (p *Pipeline) RoundTrip(in string) (out string, err error) {
+	if out, err = p.Str2Str(in); err != nil {
+		return "", err
+	}
+
+	if err = p.Verify(in, out); err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+// Verify converts the output of the pipeline back with the reverse of the pipeline and
+// returns an error wrapping ErrRoundTrip if the input is not reproduced, as RoundTrip
+// does. It can be used to verify an output that was already converted (e.g. by Trace)
+// without converting the input again, which matters for steps that are not
+// deterministic such as encryption with a random nonce.
+func (p *Pipeline) Verify(in, out string) (err error) {
+	var back string
+	if back, err = p.reverse().Str2Str(out); err != nil {
+		return fmt.Errorf("%w: could not convert %q back: %s", ErrRoundTrip, out, err)
+	}
+
+	if back == in {
+		return nil
+	}
+
+	if info := p.info(0); info == nil || !info.Capabilities.Has(Lossy) {
+		first := &Pipeline{steps: p.steps[:1], infos: p.infos[:1], limits: p.limits, registry: p.registry}
+		orig, origErr := first.Str2Bin(in)
+		conv, convErr := first.Str2Bin(back)
+		if origErr == nil && convErr == nil && bytes.Equal(orig, conv) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q was converted back to %q", ErrRoundTrip, in, back)
+}
+
+// Reverse the order of the steps without checking if the steps are reversible. Fused
+// steps of a compiled pipeline are split back into their decoders.
+func (p *Pipeline) reverse() *Pipeline {
+	inv := &Pipeline{
+		steps:    make([]Decoder, 0, len(p.steps)),
+		infos:    make([]*Info, 0, len(p.steps)),
+		limits:   p.limits,
+		registry: p.registry,
+	}
+
+	for i := len(p.steps) - 1; i >= 0; i-- {
+		if fused, ok := p.steps[i].(*fusedStep); ok {
+			inv.steps = append(inv.steps, fused.to, fused.from)
+			inv.infos = append(inv.infos, fused.toInfo, fused.fromInfo)
+			continue
+		}
+
+		inv.steps = append(inv.steps, p.steps[i])
+		inv.infos = append(inv.infos, p.info(i))
+	}
+	return inv
+}
@@ -0,0 +1,101 @@
+package binutil_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/bbengfort/binutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipelineInverse(t *testing.T) {
+	pipe, err := binutil.New("hex", "b64")
+	require.NoError(t, err)
+
+	inv, err := pipe.Inverse()
+	require.NoError(t, err)
+
+	out, err := pipe.Str2Str("deadbeef")
+	require.NoError(t, err)
+	require.Equal(t, "3q2+7w==", out)
+
+	back, err := inv.Str2Str(out)
+	require.NoError(t, err)
+	require.Equal(t, "deadbeef", back)
+
+	data, err := inv.Bin2Bin([]byte{0xde, 0xad})
+	require.NoError(t, err)
+	require.Equal(t, []byte{0xde, 0xad}, data)
+
+	// Decoder instances are assumed to be reversible
+	pipe, err = binutil.New(&binutil.Hex{}, "ulid", "uuid")
+	require.NoError(t, err)
+	_, err = pipe.Inverse()
+	require.NoError(t, err)
+
+	// Lossy steps cannot be inverted
+	pipe, err = binutil.New("ascii", "hex")
+	require.NoError(t, err)
+	_, err = pipe.Inverse()
+	require.ErrorIs(t, err, binutil.ErrIrreversible)
+	require.EqualError(t, err, "the pipeline cannot be inverted: step 0 (ascii) is lossy")
+
+	_, err = (&binutil.Pipeline{}).Inverse()
+	require.ErrorIs(t, err, binutil.ErrEmptyPipeline)
+}
+
+func TestPipelineRoundTrip(t *testing.T) {
+	pipe, err := binutil.New("ulid", "uuid")
+	require.NoError(t, err)
+
+	out, err := pipe.RoundTrip("01H3W3MX9A4AFNW55R0MNMQR6Y")
+	require.NoError(t, err)
+	require.Equal(t, "0188f83a-752a-229f-5e14-b8052b4be0de", out)
+
+	// Formatting differences are ignored if the input decodes to the same bytes
+	pipe, err = binutil.New("hex", "b64")
+	require.NoError(t, err)
+
+	out, err = pipe.RoundTrip("DEADBEEF")
+	require.NoError(t, err)
+	require.Equal(t, "3q2+7w==", out)
+
+	// Lossy conversions are detected
+	pipe, err = binutil.New("latin1", "hex")
+	require.NoError(t, err)
+
+	out, err = pipe.RoundTrip("hello")
+	require.NoError(t, err)
+	require.Equal(t, "68656c6c6f", out)
+
+	_, err = pipe.RoundTrip("héllo")
+	require.ErrorIs(t, err, binutil.ErrRoundTrip)
+
+	// Conversion errors are returned without wrapping ErrRoundTrip
+	pipe, err = binutil.New("hex", "b64")
+	require.NoError(t, err)
+
+	_, err = pipe.RoundTrip("zz")
+	require.Error(t, err)
+	require.NotErrorIs(t, err, binutil.ErrRoundTrip)
+}
+
+func TestPipelineVerify(t *testing.T) {
+	key := rand(32)
+	t.Setenv("BINUTIL_TEST_KEY", "hex:"+hex.EncodeToString(key))
+
+	// Encryption is not deterministic so the traced output is verified without
+	// converting the input again
+	pipe, err := binutil.New("aesgcm:key=env:BINUTIL_TEST_KEY", "b64")
+	require.NoError(t, err)
+
+	out, _, err := pipe.Trace("the eagle has landed")
+	require.NoError(t, err)
+	require.NoError(t, pipe.Verify("the eagle has landed", out))
+
+	err = pipe.Verify("the eagle has not landed", out)
+	require.ErrorIs(t, err, binutil.ErrRoundTrip)
+
+	err = pipe.Verify("the eagle has landed", "not base64!")
+	require.ErrorIs(t, err, binutil.ErrRoundTrip)
+}
@@ -0,0 +1,298 @@
+package binutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JSONPath selects values inside of a JSON document. A subset of JSONPath is supported
+// along with the equivalent jq-style syntax:
+//
+//	$.items[*].id     .items[].id      child keys of every element of an array
+//	$['a key'][0]     .["a key"][0]    quoted keys and array indices (negative from the end)
+//	$.*               .[]              every child of an object or array
+//	$..id             ..id             the id key at any depth (recursive descent)
+//
+// Filter expressions, slices, and unions are not supported.
+type JSONPath struct {
+	expr     string
+	segments []pathSegment
+}
+
+type pathSegment struct {
+	kind      segmentKind
+	key       string
+	index     int
+	recursive bool
+}
+
+type segmentKind uint8
+
+const (
+	childSegment segmentKind = iota
+	indexSegment
+	wildcardSegment
+)
+
+// ParseJSONPath compiles a JSONPath or jq-style selector.
+func ParseJSONPath(expr string) (_ *JSONPath, err error) {
+	path := &JSONPath{expr: expr}
+	s := strings.TrimSpace(expr)
+	if s == "" {
+		return nil, fmt.Errorf("invalid path %q: empty selector", expr)
+	}
+
+	s = strings.TrimPrefix(s, "$")
+	for len(s) > 0 {
+		seg := pathSegment{}
+		switch {
+		case strings.HasPrefix(s, ".."):
+			seg.recursive = true
+			s = s[2:]
+		case s[0] == '.':
+			s = s[1:]
+		case s[0] != '[':
+			return nil, fmt.Errorf("invalid path %q: expected . or [ at %q", expr, s)
+		}
+
+		// A jq-style identity (.) selects the root
+		if s == "" && !seg.recursive && len(path.segments) == 0 {
+			break
+		}
+
+		if s != "" && s[0] == '[' {
+			if s, err = parseBracket(s, &seg); err != nil {
+				return nil, fmt.Errorf("invalid path %q: %w", expr, err)
+			}
+		} else {
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+
+			name := s[:end]
+			s = s[end:]
+			switch name {
+			case "":
+				return nil, fmt.Errorf("invalid path %q: missing key name", expr)
+			case "*":
+				seg.kind = wildcardSegment
+			default:
+				seg.kind, seg.key = childSegment, name
+			}
+		}
+		path.segments = append(path.segments, seg)
+	}
+	return path, nil
+}
+
+// Parse a bracketed selector, e.g. [0], [*], [], ['key'], or ["key"].
+func parseBracket(s string, seg *pathSegment) (_ string, err error) {
+	s = s[1:]
+	if s != "" && (s[0] == '\'' || s[0] == '"') {
+		quote := s[0]
+		var key strings.Builder
+		for i := 1; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				if i+1 < len(s) {
+					i++
+					key.WriteByte(s[i])
+				}
+			case quote:
+				rest := strings.TrimSpace(s[i+1:])
+				if !strings.HasPrefix(rest, "]") {
+					return "", fmt.Errorf("unterminated bracket")
+				}
+				seg.kind, seg.key = childSegment, key.String()
+				return rest[1:], nil
+			default:
+				key.WriteByte(s[i])
+			}
+		}
+		return "", fmt.Errorf("unterminated quoted key")
+	}
+
+	end := strings.IndexByte(s, ']')
+	if end < 0 {
+		return "", fmt.Errorf("unterminated bracket")
+	}
+
+	inner := strings.TrimSpace(s[:end])
+	switch inner {
+	case "", "*":
+		seg.kind = wildcardSegment
+	default:
+		if seg.index, err = strconv.Atoi(inner); err != nil {
+			return "", fmt.Errorf("unsupported selector [%s]", inner)
+		}
+		seg.kind = indexSegment
+	}
+	return s[end+1:], nil
+}
+
+// String returns the expression the path was parsed from.
+func (p *JSONPath) String() string {
+	return p.expr
+}
+
+// RewriteJSON replaces every string value in the JSON document that is matched by one
+// of the paths with the result of fn, which is called with the string value and the
+// location of the value in the document (e.g. $.items[0].id). Each value is replaced
+// once even if it is matched by more than one path. Null values are skipped, as are
+// values other than strings matched by a wildcard or recursive descent (e.g. $..*), but
+// a definite path (e.g. $.items[0].id) that matches a value other than a string is an
+// error. The document is returned as compact
+// JSON with its key order and number formatting preserved, along with the number of
+// values that were replaced.
+func RewriteJSON(doc []byte, fn func(loc, val string) (string, error), paths ...*JSONPath) (_ []byte, n int, err error) {
+	var root any
+	if root, err = parseOrderedJSON(doc); err != nil {
+		return nil, 0, err
+	}
+
+	// Collect the matched values before replacing any of them so that values matched by
+	// overlapping paths are only converted once.
+	matches := &jsonMatches{seen: make(map[string]int)}
+	for _, path := range paths {
+		path.collect(root, path.segments, "$", func(v any) { root = v }, path.definite(), matches)
+	}
+
+	for _, match := range matches.values {
+		switch t := match.value.(type) {
+		case nil:
+			continue
+		case string:
+			var out string
+			if out, err = fn(match.loc, t); err != nil {
+				return nil, n, fmt.Errorf("%s: %w", match.loc, err)
+			}
+			match.set(out)
+			n++
+		default:
+			if match.definite {
+				return nil, n, fmt.Errorf("%s: expected a string value, found %s", match.loc, jsonType(t))
+			}
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err = (jsonWriter{}).write(buf, root); err != nil {
+		return nil, n, err
+	}
+	return buf.Bytes(), n, nil
+}
+
+// jsonMatches are the values matched by the paths in the order they were matched, with
+// each location in the document only matched once.
+type jsonMatches struct {
+	values []jsonMatch
+	seen   map[string]int
+}
+
+type jsonMatch struct {
+	loc      string
+	value    any
+	set      func(any)
+	definite bool
+}
+
+func (m *jsonMatches) add(match jsonMatch) {
+	if i, ok := m.seen[match.loc]; ok {
+		m.values[i].definite = m.values[i].definite || match.definite
+		return
+	}
+
+	m.seen[match.loc] = len(m.values)
+	m.values = append(m.values, match)
+}
+
+// Collect the values matched by the remaining segments, along with a function that
+// replaces the value in its parent.
+func (p *JSONPath) collect(v any, segments []pathSegment, loc string, set func(any), definite bool, matches *jsonMatches) {
+	if len(segments) == 0 {
+		matches.add(jsonMatch{loc: loc, value: v, set: set, definite: definite})
+		return
+	}
+
+	seg := segments[0]
+	if seg.recursive {
+		// Match the selector against the descendants of the value before the value itself.
+		switch t := v.(type) {
+		case *orderedMap:
+			for _, key := range t.keys {
+				p.collect(t.values[key], segments, childLocation(loc, key), t.setter(key), definite, matches)
+			}
+		case []any:
+			for i := range t {
+				p.collect(t[i], segments, fmt.Sprintf("%s[%d]", loc, i), elementSetter(t, i), definite, matches)
+			}
+		}
+	}
+
+	switch t := v.(type) {
+	case *orderedMap:
+		for _, key := range t.keys {
+			if seg.kind == wildcardSegment || (seg.kind == childSegment && seg.key == key) {
+				p.collect(t.values[key], segments[1:], childLocation(loc, key), t.setter(key), definite, matches)
+			}
+		}
+	case []any:
+		for i := range t {
+			if seg.kind == wildcardSegment || (seg.kind == indexSegment && (seg.index == i || seg.index == i-len(t))) {
+				p.collect(t[i], segments[1:], fmt.Sprintf("%s[%d]", loc, i), elementSetter(t, i), definite, matches)
+			}
+		}
+	}
+}
+
+// A path is definite if it matches at most one value, i.e. it has no wildcards and no
+// recursive descent.
+func (p *JSONPath) definite() bool {
+	for _, seg := range p.segments {
+		if seg.recursive || seg.kind == wildcardSegment {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *orderedMap) setter(key string) func(any) {
+	return func(v any) { m.values[key] = v }
+}
+
+func elementSetter(arr []any, i int) func(any) {
+	return func(v any) { arr[i] = v }
+}
+
+func childLocation(loc, key string) string {
+	for _, r := range key {
+		if !(r == '_' || r == '-' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')) {
+			quoted, _ := json.Marshal(key)
+			return fmt.Sprintf("%s[%s]", loc, quoted)
+		}
+	}
+
+	if key == "" {
+		return loc + `[""]`
+	}
+	return loc + "." + key
+}
+
+func jsonType(v any) string {
+	switch v.(type) {
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case []any:
+		return "array"
+	case *orderedMap:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
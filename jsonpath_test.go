@@ -0,0 +1,134 @@
+package binutil_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bbengfort/binutil"
+	"github.com/stretchr/testify/require"
+)
+
+const jsonPathFixture = `{"items": [{"id": "a", "n": 1.50}, {"id": "b", "tags": ["x", "y"]}, {"id": null}], "meta": {"id": "c", "a key": "d"}}`
+
+func TestJSONPath(t *testing.T) {
+	testCases := []struct {
+		expr     string
+		expected string
+		n        int
+	}{
+		{"$.items[*].id", `{"items":[{"id":"A","n":1.50},{"id":"B","tags":["x","y"]},{"id":null}],"meta":{"id":"c","a key":"d"}}`, 2},
+		{".items[].id", `{"items":[{"id":"A","n":1.50},{"id":"B","tags":["x","y"]},{"id":null}],"meta":{"id":"c","a key":"d"}}`, 2},
+		{"$.items[0].id", `{"items":[{"id":"A","n":1.50},{"id":"b","tags":["x","y"]},{"id":null}],"meta":{"id":"c","a key":"d"}}`, 1},
+		{"$.items[-2].tags[*]", `{"items":[{"id":"a","n":1.50},{"id":"b","tags":["X","Y"]},{"id":null}],"meta":{"id":"c","a key":"d"}}`, 2},
+		{"$..id", `{"items":[{"id":"A","n":1.50},{"id":"B","tags":["x","y"]},{"id":null}],"meta":{"id":"C","a key":"d"}}`, 3},
+		{"$['meta']['a key']", `{"items":[{"id":"a","n":1.50},{"id":"b","tags":["x","y"]},{"id":null}],"meta":{"id":"c","a key":"D"}}`, 1},
+		{`.meta["a key"]`, `{"items":[{"id":"a","n":1.50},{"id":"b","tags":["x","y"]},{"id":null}],"meta":{"id":"c","a key":"D"}}`, 1},
+		{"$.meta.*", `{"items":[{"id":"a","n":1.50},{"id":"b","tags":["x","y"]},{"id":null}],"meta":{"id":"C","a key":"D"}}`, 2},
+		{"$.missing[*].id", `{"items":[{"id":"a","n":1.50},{"id":"b","tags":["x","y"]},{"id":null}],"meta":{"id":"c","a key":"d"}}`, 0},
+	}
+
+	upper := func(_, val string) (string, error) { return strings.ToUpper(val), nil }
+	for _, tc := range testCases {
+		path, err := binutil.ParseJSONPath(tc.expr)
+		require.NoError(t, err, "could not parse %q", tc.expr)
+		require.Equal(t, tc.expr, path.String())
+
+		out, n, err := binutil.RewriteJSON([]byte(jsonPathFixture), upper, path)
+		require.NoError(t, err, "could not rewrite %q", tc.expr)
+		require.Equal(t, tc.expected, string(out), "unexpected document for %q", tc.expr)
+		require.Equal(t, tc.n, n, "unexpected number of matches for %q", tc.expr)
+	}
+}
+
+func TestJSONPathErrors(t *testing.T) {
+	for _, expr := range []string{"", "items", "$.items[", "$.items[?(@.id)]", "$['a", "$.a..", "$.a[0:2]"} {
+		_, err := binutil.ParseJSONPath(expr)
+		require.Error(t, err, "expected %q to be rejected", expr)
+	}
+
+	var locs []string
+	record := func(loc, val string) (string, error) {
+		locs = append(locs, loc)
+		return val, nil
+	}
+
+	path, err := binutil.ParseJSONPath("$..id")
+	require.NoError(t, err)
+
+	_, _, err = binutil.RewriteJSON([]byte(jsonPathFixture), record, path)
+	require.NoError(t, err)
+	require.Equal(t, []string{"$.items[0].id", "$.items[1].id", "$.meta.id"}, locs)
+
+	// Matching a non-string value is an error that includes the location
+	path, err = binutil.ParseJSONPath("$.items[0].n")
+	require.NoError(t, err)
+
+	_, _, err = binutil.RewriteJSON([]byte(jsonPathFixture), record, path)
+	require.EqualError(t, err, "$.items[0].n: expected a string value, found number")
+}
+
+func TestRewriteJSONOverlappingPaths(t *testing.T) {
+	// Wrapping the value shows how many times each value was converted
+	var locs []string
+	wrap := func(loc, val string) (string, error) {
+		locs = append(locs, loc)
+		return "<" + val + ">", nil
+	}
+
+	parse := func(exprs ...string) []*binutil.JSONPath {
+		paths := make([]*binutil.JSONPath, 0, len(exprs))
+		for _, expr := range exprs {
+			path, err := binutil.ParseJSONPath(expr)
+			require.NoError(t, err, "could not parse %q", expr)
+			paths = append(paths, path)
+		}
+		return paths
+	}
+
+	out, n, err := binutil.RewriteJSON([]byte(jsonPathFixture), wrap, parse("$.meta.id", "$..id", "$.items[*].id", "$.items[0].id")...)
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	require.Equal(t, []string{"$.meta.id", "$.items[0].id", "$.items[1].id"}, locs)
+	require.Equal(t, `{"items":[{"id":"<a>","n":1.50},{"id":"<b>","tags":["x","y"]},{"id":null}],"meta":{"id":"<c>","a key":"d"}}`, string(out))
+
+	// Recursive descent skips objects and arrays and converts the leaves beneath them
+	locs = nil
+	doc := `{"a": "1", "b": {"c": "2", "d": ["3", {"e": "4"}]}, "f": null}`
+	out, n, err = binutil.RewriteJSON([]byte(doc), wrap, parse("$..*", "$.b.c", "$.b.d[*]")...)
+	require.NoError(t, err)
+	require.Equal(t, 4, n)
+	require.Equal(t, []string{"$.b.d[1].e", "$.b.d[0]", "$.b.c", "$.a"}, locs)
+	require.Equal(t, `{"a":"<1>","b":{"c":"<2>","d":["<3>",{"e":"<4>"}]},"f":null}`, string(out))
+
+	// Numbers and booleans matched by a wildcard or recursive descent are skipped
+	locs = nil
+	scalars := `{"a": "1", "n": 2, "b": [true, "3", 4.5]}`
+	out, n, err = binutil.RewriteJSON([]byte(scalars), wrap, parse("$..*", "$.b[*]")...)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, `{"a":"<1>","n":2,"b":[true,"<3>",4.5]}`, string(out))
+
+	_, _, err = binutil.RewriteJSON([]byte(scalars), wrap, parse("$..*", "$.b[0]")...)
+	require.EqualError(t, err, "$.b[0]: expected a string value, found boolean")
+
+	// An object matched by a definite path is still an error
+	_, _, err = binutil.RewriteJSON([]byte(doc), wrap, parse("$..*", "$.b")...)
+	require.EqualError(t, err, "$.b: expected a string value, found object")
+}
+
+func TestRewriteJSONPipeline(t *testing.T) {
+	pipe, err := binutil.New("b64", "uuid")
+	require.NoError(t, err)
+
+	path, err := binutil.ParseJSONPath("$.items[*].id")
+	require.NoError(t, err)
+
+	convert := func(_, val string) (string, error) { return pipe.Str2Str(val) }
+	out, n, err := binutil.RewriteJSON([]byte(`{"items": [{"id": "nYOG+hjpWFAGJsaBoLrSeg=="}]}`), convert, path)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, `{"items":[{"id":"9d8386fa-18e9-5850-0626-c681a0bad27a"}]}`, string(out))
+
+	_, _, err = binutil.RewriteJSON([]byte(`{"items": [{"id": "not base64!"}]}`), convert, path)
+	require.ErrorContains(t, err, "$.items[0].id: ")
+}
@@ -0,0 +1,445 @@
+package binutil
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterOptionsDecoder(JWTDecoder, func(opts Options) (Decoder, error) { return NewJWTFromOptions(opts) }, "jws", "jwe")
+	// Tokens are decoded into their payload and a JSON rendering but cannot be signed, so
+	// the step is lossy and cannot be inverted.
+	DescribeDecoder(JWTDecoder, Info{Description: "decode and verify compact JSON web tokens", Category: CryptoCategory, Capabilities: Bidirectional | Lossy, Options: []string{"key", "secret"}})
+}
+
+const JWTDecoder = "jwt"
+
+// NewJWT returns a JWT step that decodes tokens without verifying their signatures.
+func NewJWT() *JWT {
+	return &JWT{}
+}
+
+// NewJWTFromOptions creates a JWT step from the step specification options. The key
+// option references a PEM public key or certificate, a JWK, or a JWKS used to verify
+// asymmetric signatures, and the secret option references the shared secret used to
+// verify HMAC signatures (e.g. key=file:jwks.json or secret=env:JWT_SECRET). If either
+// is specified then tokens that cannot be verified are rejected when decoded.
+func NewJWTFromOptions(opts Options) (_ *JWT, err error) {
+	j := NewJWT()
+
+	var data []byte
+	if data, err = opts.Bytes("key"); err != nil {
+		return nil, err
+	}
+
+	if data != nil {
+		if j.Keys, err = ParseJWTKeys(data); err != nil {
+			return nil, err
+		}
+	}
+
+	if j.Secret, err = opts.Bytes("secret"); err != nil {
+		return nil, err
+	}
+
+	// HMAC signatures must not be verified with an empty key
+	if j.Secret != nil && len(j.Secret) == 0 {
+		return nil, errors.New("the jwt secret must not be empty")
+	}
+	return j, nil
+}
+
+// JWT implements the encoder and decoder interface for JSON web tokens in the compact
+// serialization (JWS with three parts or JWE with five parts). Both the string and the
+// binary inputs are the compact token. The string representation is a pretty printed
+// JSON document with the decoded header, claims, and signature along with the exp, nbf,
+// and iat claims as human readable times; the binary representation is the payload.
+// JWE payloads are encrypted so only the header can be decoded.
+//
+// If Keys or a Secret are specified then the signature is verified when the token is
+// decoded and an error is returned if it is invalid. Verification supports the HS*,
+// RS*, PS*, ES* and EdDSA algorithms.
+type JWT struct {
+	Keys      []JWTKey
+	Secret    []byte
+	header    json.RawMessage
+	payload   []byte
+	signature []byte
+	encrypted bool
+	verified  bool
+}
+
+// JWTKey is a public key used to verify token signatures, with an optional key ID that
+// is matched against the kid in the token header.
+type JWTKey struct {
+	ID  string
+	Key crypto.PublicKey
+}
+
+var (
+	_ Encoder = &JWT{}
+	_ Decoder = &JWT{}
+)
+
+// DecodeBinary decodes the compact token from its bytes.
+func (j JWT) DecodeBinary(in []byte) (Encoder, error) {
+	return j.DecodeString(string(in))
+}
+
+// DecodeString splits the compact token and decodes its parts, verifying the signature
+// if a key or secret has been specified.
+func (j JWT) DecodeString(in string) (_ Encoder, err error) {
+	parts := strings.Split(strings.TrimSpace(in), ".")
+	if len(parts) != 3 && len(parts) != 5 {
+		return nil, ErrInvalidToken
+	}
+
+	out := &JWT{Keys: j.Keys, Secret: j.Secret, encrypted: len(parts) == 5}
+	b64 := NewBase64(B64SchemeRawURL)
+
+	var header []byte
+	if header, err = b64decode(b64, parts[0]); err != nil || !json.Valid(header) {
+		return nil, ErrInvalidToken
+	}
+	out.header = header
+
+	if out.encrypted {
+		if j.Keys != nil || j.Secret != nil {
+			return nil, ErrEncryptedToken
+		}
+		return out, nil
+	}
+
+	if out.payload, err = b64decode(b64, parts[1]); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if out.signature, err = b64decode(b64, parts[2]); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if j.Keys != nil || j.Secret != nil {
+		if err = out.verify(parts[0] + "." + parts[1]); err != nil {
+			return nil, err
+		}
+		out.verified = true
+	}
+	return out, nil
+}
+
+// EncodeBinary returns the decoded payload of a signed token.
+func (j JWT) EncodeBinary() ([]byte, error) {
+	if j.header == nil {
+		return nil, ErrNoData
+	}
+
+	if j.encrypted {
+		return nil, ErrEncryptedToken
+	}
+	return j.payload, nil
+}
+
+// EncodeString renders the decoded token as a pretty printed JSON document.
+func (j JWT) EncodeString() (string, error) {
+	if j.header == nil {
+		return "", ErrNoData
+	}
+
+	data, err := json.MarshalIndent(j.Decoded(), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// DecodedJWT is the JSON rendering of a decoded token.
+type DecodedJWT struct {
+	Header    json.RawMessage   `json:"header"`
+	Claims    json.RawMessage   `json:"claims,omitempty"`
+	Times     map[string]string `json:"times,omitempty"`
+	Signature string            `json:"signature,omitempty"`
+	Encrypted bool              `json:"encrypted,omitempty"`
+	Verified  bool              `json:"verified"`
+}
+
+// Decoded returns the decoded parts of the token; claims that are not JSON are
+// rendered as a JSON string.
+func (j JWT) Decoded() *DecodedJWT {
+	out := &DecodedJWT{Header: j.header, Encrypted: j.encrypted, Verified: j.verified}
+	if j.encrypted {
+		return out
+	}
+
+	if json.Valid(j.payload) {
+		out.Claims = j.payload
+	} else {
+		out.Claims, _ = json.Marshal(string(j.payload))
+	}
+
+	sig, _ := NewBase64(B64SchemeRawURL).DecodeBinary(j.signature)
+	out.Signature, _ = sig.EncodeString()
+
+	var claims map[string]any
+	if err := json.Unmarshal(j.payload, &claims); err == nil {
+		now := time.Now()
+		for _, name := range []string{"exp", "nbf", "iat"} {
+			ts, ok := claims[name].(float64)
+			if !ok {
+				continue
+			}
+
+			if out.Times == nil {
+				out.Times = make(map[string]string)
+			}
+
+			t := time.Unix(int64(ts), 0).UTC()
+			switch {
+			case name == "exp" && now.After(t):
+				out.Times[name] = t.Format(time.RFC3339) + " (expired)"
+			case name == "nbf" && now.Before(t):
+				out.Times[name] = t.Format(time.RFC3339) + " (not yet valid)"
+			default:
+				out.Times[name] = t.Format(time.RFC3339)
+			}
+		}
+	}
+	return out
+}
+
+// Verified returns true if the signature of the token was verified when decoded.
+func (j JWT) Verified() bool {
+	return j.verified
+}
+
+func (j *JWT) verify(signingInput string) (err error) {
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+
+	if err = json.Unmarshal(j.header, &header); err != nil {
+		return ErrInvalidToken
+	}
+
+	hash := jwtHashes[header.Alg]
+	if strings.HasPrefix(header.Alg, "HS") {
+		if j.Secret == nil || hash == 0 {
+			return fmt.Errorf("%w: no secret to verify %s", ErrSignatureInvalid, header.Alg)
+		}
+
+		mac := hmac.New(hash.New, j.Secret)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(mac.Sum(nil), j.signature) {
+			return ErrSignatureInvalid
+		}
+		return nil
+	}
+
+	var digest []byte
+	if hash != 0 {
+		h := hash.New()
+		h.Write([]byte(signingInput))
+		digest = h.Sum(nil)
+	}
+
+	for _, key := range j.Keys {
+		if header.Kid != "" && key.ID != "" && header.Kid != key.ID {
+			continue
+		}
+
+		switch pub := key.Key.(type) {
+		case *rsa.PublicKey:
+			switch {
+			case strings.HasPrefix(header.Alg, "RS") && hash != 0:
+				if rsa.VerifyPKCS1v15(pub, hash, digest, j.signature) == nil {
+					return nil
+				}
+			case strings.HasPrefix(header.Alg, "PS") && hash != 0:
+				if rsa.VerifyPSS(pub, hash, digest, j.signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) == nil {
+					return nil
+				}
+			}
+		case *ecdsa.PublicKey:
+			size := (pub.Curve.Params().BitSize + 7) / 8
+			if strings.HasPrefix(header.Alg, "ES") && hash != 0 && len(j.signature) == 2*size {
+				r := new(big.Int).SetBytes(j.signature[:size])
+				s := new(big.Int).SetBytes(j.signature[size:])
+				if ecdsa.Verify(pub, digest, r, s) {
+					return nil
+				}
+			}
+		case ed25519.PublicKey:
+			if header.Alg == "EdDSA" && ed25519.Verify(pub, []byte(signingInput), j.signature) {
+				return nil
+			}
+		}
+	}
+
+	switch {
+	case header.Alg == "none":
+		return fmt.Errorf("%w: unsigned tokens cannot be verified", ErrSignatureInvalid)
+	case hash == 0 && header.Alg != "EdDSA":
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrSignatureInvalid, header.Alg)
+	default:
+		return ErrSignatureInvalid
+	}
+}
+
+// The hash used by each of the supported signature algorithms; EdDSA signs the signing
+// input directly and so does not require a hash.
+var jwtHashes = map[string]crypto.Hash{
+	"HS256": crypto.SHA256, "HS384": crypto.SHA384, "HS512": crypto.SHA512,
+	"RS256": crypto.SHA256, "RS384": crypto.SHA384, "RS512": crypto.SHA512,
+	"PS256": crypto.SHA256, "PS384": crypto.SHA384, "PS512": crypto.SHA512,
+	"ES256": crypto.SHA256, "ES384": crypto.SHA384, "ES512": crypto.SHA512,
+}
+
+func b64decode(b64 *Base64, in string) ([]byte, error) {
+	enc, err := b64.DecodeString(in)
+	if err != nil {
+		return nil, err
+	}
+	return enc.EncodeBinary()
+}
+
+// ParseJWTKeys parses verification keys from a PEM encoded public key or certificate, a
+// single JSON web key, or a JSON web key set. Symmetric (oct) JWKs are not supported;
+// HMAC secrets should be specified as the JWT Secret instead.
+func ParseJWTKeys(data []byte) (keys []JWTKey, err error) {
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("{")) {
+		var set struct {
+			Keys []json.RawMessage `json:"keys"`
+		}
+
+		if err = json.Unmarshal(data, &set); err != nil {
+			return nil, err
+		}
+
+		if set.Keys == nil {
+			set.Keys = []json.RawMessage{data}
+		}
+
+		for _, raw := range set.Keys {
+			var key JWTKey
+			if key, err = parseJWK(raw); err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+		}
+	} else {
+		if keys, err = parsePEMKeys(data); err != nil {
+			return nil, err
+		}
+	}
+
+	// An empty key set must not disable verification
+	if len(keys) == 0 {
+		return nil, errors.New("no public keys found in the pem, jwk, or jwks data")
+	}
+	return keys, nil
+}
+
+func parsePEMKeys(data []byte) (keys []JWTKey, err error) {
+	for {
+		var block *pem.Block
+		if block, data = pem.Decode(data); block == nil {
+			break
+		}
+
+		switch block.Type {
+		case "CERTIFICATE":
+			var cert *x509.Certificate
+			if cert, err = x509.ParseCertificate(block.Bytes); err != nil {
+				return nil, err
+			}
+			keys = append(keys, JWTKey{Key: cert.PublicKey})
+		case "RSA PUBLIC KEY":
+			var pub *rsa.PublicKey
+			if pub, err = x509.ParsePKCS1PublicKey(block.Bytes); err != nil {
+				return nil, err
+			}
+			keys = append(keys, JWTKey{Key: pub})
+		default:
+			var pub any
+			if pub, err = x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+				return nil, err
+			}
+			keys = append(keys, JWTKey{Key: pub})
+		}
+	}
+	return keys, nil
+}
+
+func parseJWK(data []byte) (_ JWTKey, err error) {
+	var jwk struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Crv string `json:"crv"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+	}
+
+	if err = json.Unmarshal(data, &jwk); err != nil {
+		return JWTKey{}, err
+	}
+
+	b64 := NewBase64(B64SchemeRawURL)
+	params := make(map[string][]byte, 4)
+	for name, val := range map[string]string{"n": jwk.N, "e": jwk.E, "x": jwk.X, "y": jwk.Y} {
+		if val == "" {
+			continue
+		}
+
+		if params[name], err = b64decode(b64, val); err != nil {
+			return JWTKey{}, fmt.Errorf("invalid jwk parameter %q: %w", name, err)
+		}
+	}
+
+	key := JWTKey{ID: jwk.Kid}
+	switch jwk.Kty {
+	case "RSA":
+		if params["n"] == nil || params["e"] == nil {
+			return JWTKey{}, errors.New("rsa jwk requires n and e parameters")
+		}
+		key.Key = &rsa.PublicKey{N: new(big.Int).SetBytes(params["n"]), E: int(new(big.Int).SetBytes(params["e"]).Int64())}
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return JWTKey{}, fmt.Errorf("unsupported jwk curve %q", jwk.Crv)
+		}
+		key.Key = &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(params["x"]), Y: new(big.Int).SetBytes(params["y"])}
+	case "OKP":
+		if jwk.Crv != "Ed25519" || len(params["x"]) != ed25519.PublicKeySize {
+			return JWTKey{}, fmt.Errorf("unsupported jwk curve %q", jwk.Crv)
+		}
+		key.Key = ed25519.PublicKey(params["x"])
+	default:
+		return JWTKey{}, fmt.Errorf("unsupported jwk key type %q", jwk.Kty)
+	}
+	return key, nil
+}
@@ -0,0 +1,225 @@
+package binutil_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bbengfort/binutil"
+	"github.com/stretchr/testify/require"
+)
+
+const testClaims = `{"sub":"1234567890","name":"Jane Doe","iat":1516239022,"exp":1516242622}`
+
+func TestJWTDecode(t *testing.T) {
+	token := signJWT(t, "HS256", "", []byte("secret"))
+
+	enc, err := binutil.NewJWT().DecodeString(token)
+	require.NoError(t, err, "could not decode token")
+
+	claims, err := enc.EncodeBinary()
+	require.NoError(t, err, "could not encode payload")
+	require.JSONEq(t, testClaims, string(claims))
+
+	out, err := enc.EncodeString()
+	require.NoError(t, err, "could not render token")
+
+	var decoded binutil.DecodedJWT
+	require.NoError(t, json.Unmarshal([]byte(out), &decoded))
+	require.JSONEq(t, `{"alg":"HS256","typ":"JWT"}`, string(decoded.Header))
+	require.JSONEq(t, testClaims, string(decoded.Claims))
+	require.Equal(t, "2018-01-18T01:30:22Z", decoded.Times["iat"])
+	require.Equal(t, "2018-01-18T02:30:22Z (expired)", decoded.Times["exp"])
+	require.False(t, decoded.Verified)
+
+	// The payload can be passed to the next step in the pipeline
+	pipe, err := binutil.New("jwt", "text")
+	require.NoError(t, err)
+	out, err = pipe.Str2Str(token)
+	require.NoError(t, err)
+	require.JSONEq(t, testClaims, out)
+
+	for _, invalid := range []string{"", "abc", "a.b", "!!!.e30.", "e30.!!!.e30"} {
+		_, err = binutil.NewJWT().DecodeString(invalid)
+		require.ErrorIs(t, err, binutil.ErrInvalidToken, "expected %q to be an invalid token", invalid)
+	}
+}
+
+func TestJWTVerify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(crand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), crand.Reader)
+	require.NoError(t, err)
+	edPub, edKey, err := ed25519.GenerateKey(crand.Reader)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		alg    string
+		signer any
+		public crypto.PublicKey
+	}{
+		{"RS256", rsaKey, &rsaKey.PublicKey},
+		{"RS512", rsaKey, &rsaKey.PublicKey},
+		{"PS256", rsaKey, &rsaKey.PublicKey},
+		{"ES256", ecKey, &ecKey.PublicKey},
+		{"EdDSA", edKey, edPub},
+	}
+
+	for _, tc := range testCases {
+		token := signJWT(t, tc.alg, "", tc.signer)
+
+		// Verify using a PEM encoded public key
+		der, err := x509.MarshalPKIXPublicKey(tc.public)
+		require.NoError(t, err)
+		path := filepath.Join(t.TempDir(), "key.pem")
+		require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+
+		dec, err := binutil.NewDecoder("jwt:key=file:" + path)
+		require.NoError(t, err, "could not create jwt decoder for %s", tc.alg)
+
+		enc, err := dec.DecodeString(token)
+		require.NoError(t, err, "could not verify %s token", tc.alg)
+		require.True(t, enc.(*binutil.JWT).Verified())
+
+		// Tampering with the claims must fail verification
+		parts := strings.Split(token, ".")
+		parts[1] = base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"admin"}`))
+		_, err = dec.DecodeString(parts[0] + "." + parts[1] + "." + parts[2])
+		require.ErrorIs(t, err, binutil.ErrSignatureInvalid, "expected tampered %s token to fail", tc.alg)
+	}
+
+	// Verification with an HMAC secret
+	t.Setenv("BINUTIL_JWT_SECRET", "secret")
+	dec, err := binutil.NewDecoder("jwt:secret=env:BINUTIL_JWT_SECRET")
+	require.NoError(t, err)
+	_, err = dec.DecodeString(signJWT(t, "HS256", "", []byte("secret")))
+	require.NoError(t, err, "could not verify hmac token")
+	_, err = dec.DecodeString(signJWT(t, "HS256", "", []byte("wrong")))
+	require.ErrorIs(t, err, binutil.ErrSignatureInvalid)
+
+	// A public key must not be usable as an HMAC secret
+	_, err = binutil.JWT{Keys: []binutil.JWTKey{{Key: &rsaKey.PublicKey}}}.DecodeString(signJWT(t, "HS256", "", []byte("secret")))
+	require.ErrorIs(t, err, binutil.ErrSignatureInvalid)
+}
+
+func TestJWTKeySet(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), crand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(crand.Reader, 2048)
+	require.NoError(t, err)
+	edPub, _, err := ed25519.GenerateKey(crand.Reader)
+	require.NoError(t, err)
+
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwks := fmt.Sprintf(`{"keys":[
+		{"kty":"OKP","crv":"Ed25519","kid":"ed","x":%q},
+		{"kty":"RSA","kid":"rsa","n":%q,"e":"AQAB"},
+		{"kty":"EC","crv":"P-256","kid":"ec","x":%q,"y":%q}
+	]}`, b64(edPub), b64(rsaKey.N.Bytes()), b64(ecKey.X.FillBytes(make([]byte, 32))), b64(ecKey.Y.FillBytes(make([]byte, 32))))
+
+	keys, err := binutil.ParseJWTKeys([]byte(jwks))
+	require.NoError(t, err, "could not parse jwks")
+	require.Len(t, keys, 3)
+
+	jwt := binutil.JWT{Keys: keys}
+	_, err = jwt.DecodeString(signJWT(t, "ES256", "ec", ecKey))
+	require.NoError(t, err, "could not verify token with matching kid")
+
+	_, err = jwt.DecodeString(signJWT(t, "RS256", "rsa", rsaKey))
+	require.NoError(t, err, "could not verify token with matching kid")
+
+	_, err = jwt.DecodeString(signJWT(t, "ES256", "ed", ecKey))
+	require.ErrorIs(t, err, binutil.ErrSignatureInvalid, "expected mismatched kid to fail")
+
+	_, err = binutil.ParseJWTKeys([]byte(`{"kty":"oct","k":"c2VjcmV0"}`))
+	require.Error(t, err, "expected symmetric jwk to be unsupported")
+
+	// An empty key set must not disable verification
+	for _, data := range []string{`{"keys":[]}`, ` {"keys": []} `, ""} {
+		_, err = binutil.ParseJWTKeys([]byte(data))
+		require.EqualError(t, err, "no public keys found in the pem, jwk, or jwks data", "expected %q to be rejected", data)
+	}
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"keys":[]}`), 0600))
+	_, err = binutil.NewDecoder("jwt:key=file:" + path)
+	require.Error(t, err, "expected an empty jwks to be rejected")
+
+	// An empty secret must not be used to verify HMAC signatures
+	t.Setenv("BINUTIL_JWT_SECRET", "")
+	_, err = binutil.NewDecoder("jwt:secret=env:BINUTIL_JWT_SECRET")
+	require.EqualError(t, err, "the jwt secret must not be empty")
+
+	_, err = binutil.NewDecoder("jwt:secret=")
+	require.EqualError(t, err, "the jwt secret must not be empty")
+}
+
+func TestJWE(t *testing.T) {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RSA-OAEP","enc":"A256GCM"}`))
+	token := header + ".a2V5.aXY.Y2lwaGVydGV4dA.dGFn"
+
+	enc, err := binutil.NewJWT().DecodeString(token)
+	require.NoError(t, err, "could not decode jwe header")
+
+	out, err := enc.EncodeString()
+	require.NoError(t, err)
+	require.Contains(t, out, `"encrypted": true`)
+
+	_, err = enc.EncodeBinary()
+	require.ErrorIs(t, err, binutil.ErrEncryptedToken)
+}
+
+// Create a compact JWS of the test claims signed with the specified algorithm and key.
+func signJWT(t *testing.T, alg, kid string, key any) string {
+	header := map[string]string{"alg": alg, "typ": "JWT"}
+	if kid != "" {
+		header["kid"] = kid
+	}
+
+	hdr, err := json.Marshal(header)
+	require.NoError(t, err)
+
+	input := base64.RawURLEncoding.EncodeToString(hdr) + "." + base64.RawURLEncoding.EncodeToString([]byte(testClaims))
+	digest := sha256.Sum256([]byte(input))
+
+	var sig []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(input))
+		sig = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		switch alg {
+		case "RS256":
+			sig, err = rsa.SignPKCS1v15(crand.Reader, k, crypto.SHA256, digest[:])
+		case "RS512":
+			h := crypto.SHA512.New()
+			h.Write([]byte(input))
+			sig, err = rsa.SignPKCS1v15(crand.Reader, k, crypto.SHA512, h.Sum(nil))
+		case "PS256":
+			sig, err = rsa.SignPSS(crand.Reader, k, crypto.SHA256, digest[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+	case *ecdsa.PrivateKey:
+		r, s, serr := ecdsa.Sign(crand.Reader, k, digest[:])
+		err = serr
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case ed25519.PrivateKey:
+		sig = ed25519.Sign(k, []byte(input))
+	}
+	require.NoError(t, err, "could not sign jwt")
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
@@ -0,0 +1,85 @@
+package binutil
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Limits bound the resources used by a pipeline so that untrusted input (e.g. in a web
+// service) cannot exhaust memory or time. Zero values disable each limit. The limits
+// are checked before and after every step: a step that is already running is not
+// interrupted, but its output is rejected before it is passed to the next step.
+type Limits struct {
+	// The maximum size in bytes of the pipeline input.
+	MaxInput int
+
+	// The maximum size in bytes of the output of any step, including the final output.
+	MaxOutput int
+
+	// The maximum ratio of the output size of any step to the size of the pipeline input.
+	MaxExpansion float64
+
+	// The maximum duration of a conversion. The timeout is only checked between steps
+	// (like the context of the Context variants of the conversion methods), so a single
+	// slow step can exceed it and the conversion fails with ErrTimeout once it returns.
+	Timeout time.Duration
+}
+
+// WithLimits bounds the conversions performed by pipelines created by New or NewMultiOutputs.
+func WithLimits(limits Limits) PipelineOption {
+	return func(conf *pipelineConfig) {
+		conf.limits = limits
+	}
+}
+
+// Limits returns the limits that the pipeline was created with.
+func (p *Pipeline) Limits() Limits {
+	return p.limits
+}
+
+// limitCheck tracks the limits of a single conversion.
+type limitCheck struct {
+	limits   Limits
+	size     int
+	deadline time.Time
+}
+
+func (l Limits) start(size int) *limitCheck {
+	check := &limitCheck{limits: l, size: size}
+	if l.Timeout > 0 {
+		check.deadline = time.Now().Add(l.Timeout)
+	}
+	return check
+}
+
+func (c *limitCheck) input(size int) error {
+	if c.limits.MaxInput > 0 && size > c.limits.MaxInput {
+		return fmt.Errorf("%w: input is %d bytes, limit is %d bytes", ErrInputTooLarge, size, c.limits.MaxInput)
+	}
+	return nil
+}
+
+func (c *limitCheck) output(step, size int) error {
+	if c.limits.MaxOutput > 0 && size > c.limits.MaxOutput {
+		return fmt.Errorf("%w: step %d produced %d bytes, limit is %d bytes", ErrOutputTooLarge, step, size, c.limits.MaxOutput)
+	}
+
+	if c.limits.MaxExpansion > 0 && c.size > 0 {
+		if ratio := float64(size) / float64(c.size); ratio > c.limits.MaxExpansion {
+			return fmt.Errorf("%w: step %d expanded the input by %.1fx, limit is %.1fx", ErrExpansionTooLarge, step, ratio, c.limits.MaxExpansion)
+		}
+	}
+	return nil
+}
+
+func (c *limitCheck) context(ctx context.Context, step int) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("conversion stopped at step %d: %w", step, err)
+	}
+
+	if !c.deadline.IsZero() && time.Now().After(c.deadline) {
+		return fmt.Errorf("%w: conversion took longer than %s at step %d", ErrTimeout, c.limits.Timeout, step)
+	}
+	return nil
+}
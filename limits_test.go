@@ -0,0 +1,90 @@
+package binutil_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bbengfort/binutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipelineLimits(t *testing.T) {
+	limits := binutil.Limits{MaxInput: 32, MaxOutput: 64, MaxExpansion: 1.5}
+	pipe, err := binutil.New("b64", "hex", binutil.WithLimits(limits))
+	require.NoError(t, err)
+	require.Equal(t, limits, pipe.Limits())
+
+	out, err := pipe.Str2Str("3q2+7w==")
+	require.NoError(t, err)
+	require.Equal(t, "deadbeef", out)
+
+	_, err = pipe.Str2Str(strings.Repeat("A", 36))
+	require.ErrorIs(t, err, binutil.ErrInputTooLarge)
+	require.EqualError(t, err, "the input exceeds the maximum input size of the pipeline: input is 36 bytes, limit is 32 bytes")
+
+	_, err = pipe.Bin2Bin(make([]byte, 33))
+	require.ErrorIs(t, err, binutil.ErrInputTooLarge)
+
+	// Hex encoding 30 bytes produces 60 characters, an expansion of 2x
+	_, err = pipe.Bin2Str(make([]byte, 30))
+	require.ErrorIs(t, err, binutil.ErrExpansionTooLarge)
+	require.EqualError(t, err, "the output of a step exceeds the maximum expansion ratio of the pipeline: step 1 expanded the input by 2.0x, limit is 1.5x")
+
+	pipe, err = binutil.New("hex", "hex", binutil.WithLimits(binutil.Limits{MaxOutput: 40}))
+	require.NoError(t, err)
+
+	_, err = pipe.Bin2Str(make([]byte, 24))
+	require.ErrorIs(t, err, binutil.ErrOutputTooLarge)
+	require.EqualError(t, err, "the output of a step exceeds the maximum output size of the pipeline: step 1 produced 48 bytes, limit is 40 bytes")
+
+	// Limits are passed to each pipeline of a multi pipeline
+	outputs := []binutil.Output{{Label: "hex", Steps: []any{"hex"}}, {Label: "b64", Steps: []any{"b64"}}}
+	multi, err := binutil.NewMultiOutputs(outputs, binutil.WithLimits(binutil.Limits{MaxInput: 4}))
+	require.NoError(t, err)
+
+	_, err = multi.Bin2Str("hex", make([]byte, 5))
+	require.ErrorIs(t, err, binutil.ErrInputTooLarge)
+}
+
+func TestPipelineContext(t *testing.T) {
+	pipe, err := binutil.New("hex", slowHex{delay: 20 * time.Millisecond}, "b64")
+	require.NoError(t, err)
+
+	out, err := pipe.Str2StrContext(context.Background(), "deadbeef")
+	require.NoError(t, err)
+	require.Equal(t, "3q2+7w==", out)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = pipe.Str2BinContext(ctx, "deadbeef")
+	require.ErrorIs(t, err, context.Canceled)
+	require.EqualError(t, err, "conversion stopped at step 0: context canceled")
+
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+
+	_, err = pipe.Bin2StrContext(ctx, []byte{0xde, 0xad})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The timeout limit is distinct from the context deadline
+	pipe, err = binutil.New("hex", slowHex{delay: 20 * time.Millisecond}, "b64", binutil.WithLimits(binutil.Limits{Timeout: 5 * time.Millisecond}))
+	require.NoError(t, err)
+
+	_, err = pipe.Bin2BinContext(context.Background(), []byte{0xde, 0xad})
+	require.ErrorIs(t, err, binutil.ErrTimeout)
+	require.NotErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// slowHex is a hex decoder that takes time to decode binary data.
+type slowHex struct {
+	binutil.Hex
+	delay time.Duration
+}
+
+func (s slowHex) DecodeBinary(in []byte) (binutil.Encoder, error) {
+	time.Sleep(s.delay)
+	return s.Hex.DecodeBinary(in)
+}
@@ -0,0 +1,103 @@
+package binutil
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func init() {
+	RegisterOptionsDecoder(MsgPackDecoder, func(opts Options) (Decoder, error) { return NewMsgPackFromOptions(opts) }, "messagepack", "mpk")
+	DescribeDecoder(MsgPackDecoder, Info{Description: "MessagePack data as JSON", Category: StructuredCategory, Capabilities: Bidirectional | Lossy, Options: []string{"pretty"}})
+}
+
+const MsgPackDecoder = "msgpack"
+
+// NewMsgPackFromOptions creates a MsgPack step from the step specification options. The
+// pretty flag indents the JSON string representation.
+func NewMsgPackFromOptions(opts Options) (_ *MsgPack, err error) {
+	m := &MsgPack{}
+	if m.Pretty, err = opts.Bool("pretty"); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// MsgPack implements the encoder and decoder interface for MessagePack data. The binary
+// representation is the MessagePack encoding and the string representation is JSON, so
+// JSON documents can be converted into MessagePack and vice versa. Binary data is
+// rendered as base64 strings and maps with non-string keys have their keys formatted as
+// strings in the JSON representation.
+type MsgPack struct {
+	Pretty bool
+	data   []byte
+	value  any
+}
+
+var (
+	_ Encoder = &MsgPack{}
+	_ Decoder = &MsgPack{}
+)
+
+// DecodeBinary decodes the MessagePack data into a generic value. The data must contain
+// exactly one value; concatenated values or trailing bytes are rejected.
+func (m MsgPack) DecodeBinary(in []byte) (_ Encoder, err error) {
+	reader := bytes.NewReader(in)
+	decoder := msgpack.NewDecoder(reader)
+	decoder.SetMapDecoder(func(d *msgpack.Decoder) (any, error) {
+		return d.DecodeUntypedMap()
+	})
+
+	out := &MsgPack{Pretty: m.Pretty, data: in}
+	if out.value, err = decoder.DecodeInterface(); err != nil {
+		return nil, err
+	}
+
+	if reader.Len() > 0 {
+		return nil, fmt.Errorf("%w: %d bytes after the msgpack value", ErrTrailingData, reader.Len())
+	}
+	return out, nil
+}
+
+// DecodeString parses the JSON document and encodes it as MessagePack with map keys
+// sorted and integers compacted.
+func (m MsgPack) DecodeString(in string) (_ Encoder, err error) {
+	out := &MsgPack{Pretty: m.Pretty}
+	if out.value, err = parseJSON([]byte(in)); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	encoder := msgpack.NewEncoder(&buf)
+	encoder.SetSortMapKeys(true)
+	encoder.UseCompactInts(true)
+
+	if err = encoder.Encode(out.value); err != nil {
+		return nil, err
+	}
+
+	out.data = buf.Bytes()
+	return out, nil
+}
+
+// EncodeBinary returns the MessagePack encoding.
+func (m MsgPack) EncodeBinary() ([]byte, error) {
+	if m.data == nil {
+		return nil, ErrNoData
+	}
+	return m.data, nil
+}
+
+// EncodeString renders the value as JSON.
+func (m MsgPack) EncodeString() (string, error) {
+	if m.data == nil {
+		return "", ErrNoData
+	}
+
+	data, err := marshalJSON(m.value, m.Pretty)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
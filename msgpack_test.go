@@ -0,0 +1,69 @@
+package binutil_test
+
+import (
+	"testing"
+
+	"github.com/bbengfort/binutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMsgPack(t *testing.T) {
+	pipe, err := binutil.New("msgpack")
+	require.NoError(t, err, "could not create msgpack pipeline")
+
+	// {"a": 1, "b": [true, nil, "c"]}
+	data := []byte{0x82, 0xa1, 0x61, 0x01, 0xa1, 0x62, 0x93, 0xc3, 0xc0, 0xa1, 0x63}
+	s, err := pipe.Bin2Str(data)
+	require.NoError(t, err, "could not convert msgpack to json")
+	require.JSONEq(t, `{"a": 1, "b": [true, null, "c"]}`, s)
+
+	out, err := pipe.Str2Bin(s)
+	require.NoError(t, err, "could not convert json to msgpack")
+	require.Equal(t, data, out, "expected sorted keys and compact integers")
+
+	// Large integers should not lose precision through the JSON bridge
+	out, err = pipe.Str2Bin(`{"big": 18446744073709551615, "neg": -9007199254740993, "pi": 3.14}`)
+	require.NoError(t, err, "could not convert json to msgpack")
+	s, err = pipe.Bin2Str(out)
+	require.NoError(t, err, "could not convert msgpack to json")
+	require.JSONEq(t, `{"big": 18446744073709551615, "neg": -9007199254740993, "pi": 3.14}`, s)
+
+	// Maps with non-string keys are rendered with string keys
+	s, err = pipe.Bin2Str([]byte{0x81, 0x01, 0xa1, 0x61})
+	require.NoError(t, err, "could not convert msgpack with integer keys to json")
+	require.JSONEq(t, `{"1": "a"}`, s)
+
+	_, err = pipe.Str2Bin(`{"a": 1} trailing`)
+	require.Error(t, err, "expected trailing data to be rejected")
+
+	_, err = pipe.Bin2Str([]byte{0xc1})
+	require.Error(t, err, "expected invalid msgpack to be rejected")
+
+	// Concatenated values or trailing bytes are not silently dropped
+	_, err = pipe.Bin2Str(append(data, 0x01))
+	require.ErrorIs(t, err, binutil.ErrTrailingData)
+
+	_, err = pipe.Bin2Str([]byte{0x01, 0x02})
+	require.ErrorIs(t, err, binutil.ErrTrailingData)
+
+	// The zero value has no data to encode
+	_, err = (binutil.MsgPack{}).EncodeString()
+	require.ErrorIs(t, err, binutil.ErrNoData)
+
+	_, err = (binutil.MsgPack{}).EncodeBinary()
+	require.ErrorIs(t, err, binutil.ErrNoData)
+
+	// A null value is data
+	s, err = pipe.Bin2Str([]byte{0xc0})
+	require.NoError(t, err)
+	require.Equal(t, "null", s)
+}
+
+func TestMsgPackPretty(t *testing.T) {
+	pipe, err := binutil.New("msgpack:pretty")
+	require.NoError(t, err, "could not create msgpack pipeline")
+
+	s, err := pipe.Bin2Str([]byte{0x81, 0xa1, 0x61, 0x01})
+	require.NoError(t, err, "could not convert msgpack to json")
+	require.Equal(t, "{\n  \"a\": 1\n}", s)
+}
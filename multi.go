@@ -1,27 +1,149 @@
 package binutil
 
-import "fmt"
+import (
+	"fmt"
+	"sync"
+)
 
-func NewMulti(steps ...string) (_ *MultiPipeline, err error) {
-	pipes := make(map[string]*Pipeline, len(steps))
-	for _, step := range steps {
+// NewMulti returns a multi pipeline with an output for each decoder specification,
+// labeled by the specification. A specification that is repeated replaces the earlier
+// output in its position. Use NewMultiOutputs to label the outputs, to give an output
+// the steps of a full pipeline, or to specify pipeline options.
+func NewMulti(names ...string) (_ *MultiPipeline, err error) {
+	multi := &MultiPipeline{
+		outputs: make([]multiOutput, 0, len(names)),
+		labels:  make(map[string]*Pipeline, len(names)),
+	}
+
+	for _, name := range names {
 		var pipe *Pipeline
-		if pipe, err = New(step); err != nil {
+		if pipe, err = New(name); err != nil {
 			return nil, err
 		}
-		pipes[step] = pipe
+		multi.add(name, pipe)
+	}
+	return multi, nil
+}
+
+// NewMultiOutputs returns a multi pipeline that converts the same input with each of the
+// labeled outputs. The label of each output must be unique. The WithRegistry option may
+// be specified to look up the decoders in a registry other than the DefaultRegistry and
+// the WithLimits option bounds the conversions of every output; options in the steps of
+// an output override these options for that output.
+func NewMultiOutputs(outputs []Output, opts ...PipelineOption) (_ *MultiPipeline, err error) {
+	conf := &pipelineConfig{registry: DefaultRegistry}
+	for _, opt := range opts {
+		opt(conf)
 	}
-	return &MultiPipeline{steps: pipes}, nil
+
+	multi := &MultiPipeline{
+		outputs: make([]multiOutput, 0, len(outputs)),
+		labels:  make(map[string]*Pipeline, len(outputs)),
+	}
+
+	for _, output := range outputs {
+		if output.Label == "" {
+			return nil, ErrNoOutputLabel
+		}
+
+		if _, ok := multi.labels[output.Label]; ok {
+			return nil, fmt.Errorf("%w: %q", ErrDuplicateOutput, output.Label)
+		}
+
+		args := make([]any, 0, len(output.Steps)+2)
+		args = append(args, WithRegistry(conf.registry), WithLimits(conf.limits))
+		args = append(args, output.Steps...)
+
+		var pipe *Pipeline
+		if pipe, err = New(args...); err != nil {
+			return nil, fmt.Errorf("output %q: %w", output.Label, err)
+		}
+		multi.add(output.Label, pipe)
+	}
+	return multi, nil
+}
+
+// Output is a labeled pipeline of a multi pipeline. The steps are the same as the steps
+// passed to New; e.g. Output{Label: "id", Steps: []any{"ulid", "uuid"}} validates that
+// the input is 16 bytes (as a ULID) before encoding it as a UUID.
+type Output struct {
+	Label string
+	Steps []any
+}
+
+// Result is the conversion of the input of a multi pipeline by one of its outputs.
+type Result struct {
+	Label  string
+	Output string
+	Err    error
 }
 
 // MultiPipeline is able to convert input data type to multiple output types.
 type MultiPipeline struct {
-	steps map[string]*Pipeline
+	outputs []multiOutput
+	labels  map[string]*Pipeline
+}
+
+type multiOutput struct {
+	label string
+	pipe  *Pipeline
+}
+
+// add appends the output or replaces the pipeline of the output with the same label.
+func (p *MultiPipeline) add(label string, pipe *Pipeline) {
+	p.labels[label] = pipe
+	for i := range p.outputs {
+		if p.outputs[i].label == label {
+			p.outputs[i].pipe = pipe
+			return
+		}
+	}
+	p.outputs = append(p.outputs, multiOutput{label: label, pipe: pipe})
+}
+
+// Labels returns the labels of the outputs in the order they were given.
+func (p *MultiPipeline) Labels() []string {
+	labels := make([]string, 0, len(p.outputs))
+	for _, output := range p.outputs {
+		labels = append(labels, output.label)
+	}
+	return labels
+}
+
+// BinToAll converts the binary input with every output concurrently and returns the
+// string results in the order the outputs were given. A failed output does
+// not affect the other outputs; its error is returned in its result.
+func (p *MultiPipeline) BinToAll(in []byte) []Result {
+	return p.all(func(pipe *Pipeline) (string, error) { return pipe.Bin2Str(in) })
+}
+
+// StrToAll converts the string input with every output concurrently and returns the
+// string results in the order the outputs were given. A failed output does
+// not affect the other outputs; its error is returned in its result.
+func (p *MultiPipeline) StrToAll(in string) []Result {
+	return p.all(func(pipe *Pipeline) (string, error) { return pipe.Str2Str(in) })
+}
+
+func (p *MultiPipeline) all(convert func(*Pipeline) (string, error)) []Result {
+	results := make([]Result, len(p.outputs))
+
+	var wg sync.WaitGroup
+	wg.Add(len(p.outputs))
+	for i, output := range p.outputs {
+		go func(i int, output multiOutput) {
+			defer wg.Done()
+			results[i].Label = output.label
+			results[i].Output, results[i].Err = convert(output.pipe)
+		}(i, output)
+	}
+
+	wg.Wait()
+	return results
 }
 
 // Bin2Bin transforms binary input data into binary output data for the named step.
 func (p *MultiPipeline) Bin2Bin(name string, in []byte) (_ []byte, err error) {
-	pipe, ok := p.steps[name]
+	pipe, ok := p.labels[name]
 	if !ok {
 		return nil, fmt.Errorf("no pipeline named %q", name)
 	}
@@ -30,7 +152,7 @@ func (p *MultiPipeline) Bin2Bin(name string, in []byte) (_ []byte, err error) {
 
 // Bin2Str transforms binary input data into a string representation for the named step.
 func (p *MultiPipeline) Bin2Str(name string, in []byte) (out string, err error) {
-	pipe, ok := p.steps[name]
+	pipe, ok := p.labels[name]
 	if !ok {
 		return "", fmt.Errorf("no pipeline named %q", name)
 	}
@@ -39,7 +161,7 @@ func (p *MultiPipeline) Bin2Str(name string, in []byte) (out string, err error)
 
 // Str2Bin transforms binary input data into binary output data for the named step.
 func (p *MultiPipeline) Str2Bin(name, in string) (out []byte, err error) {
-	pipe, ok := p.steps[name]
+	pipe, ok := p.labels[name]
 	if !ok {
 		return nil, fmt.Errorf("no pipeline named %q", name)
 	}
@@ -48,7 +170,7 @@ func (p *MultiPipeline) Str2Bin(name, in string) (out []byte, err error) {
 
 // Str2Str transforms string input data into a different string representation for the named step.
 func (p *MultiPipeline) Str2Str(name, in string) (out string, err error) {
-	pipe, ok := p.steps[name]
+	pipe, ok := p.labels[name]
 	if !ok {
 		return "", fmt.Errorf("no pipeline named %q", name)
 	}
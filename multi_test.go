@@ -0,0 +1,116 @@
+package binutil_test
+
+import (
+	"testing"
+
+	"github.com/bbengfort/binutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiPipeline(t *testing.T) {
+	multi, err := binutil.NewMultiOutputs([]binutil.Output{
+		{Label: "hex", Steps: []any{"hex"}},
+		{Label: "b64", Steps: []any{"b64"}},
+		{Label: "ulid", Steps: []any{"ulid"}},
+		{Label: "id", Steps: []any{"ulid", "uuid"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"hex", "b64", "ulid", "id"}, multi.Labels())
+
+	id := []byte{0x01, 0x88, 0xf8, 0x3a, 0x75, 0x2a, 0x22, 0x9f, 0x5e, 0x14, 0xb8, 0x05, 0x2b, 0x4b, 0xe0, 0xde}
+	results := multi.BinToAll(id)
+	require.Len(t, results, 4)
+	require.Equal(t, binutil.Result{Label: "hex", Output: "0188f83a752a229f5e14b8052b4be0de"}, results[0])
+	require.Equal(t, binutil.Result{Label: "b64", Output: "AYj4OnUqIp9eFLgFK0vg3g=="}, results[1])
+	require.Equal(t, binutil.Result{Label: "ulid", Output: "01H3W3MX9A4AFNW55R0MNMQR6Y"}, results[2])
+	require.Equal(t, binutil.Result{Label: "id", Output: "0188f83a-752a-229f-5e14-b8052b4be0de"}, results[3])
+
+	// A failed output does not affect the others
+	results = multi.BinToAll([]byte{0xde, 0xad})
+	require.Len(t, results, 4)
+	require.NoError(t, results[0].Err)
+	require.Equal(t, "dead", results[0].Output)
+	require.NoError(t, results[1].Err)
+	require.Equal(t, "3q0=", results[1].Output)
+	require.Error(t, results[2].Err)
+	require.Equal(t, "ulid", results[2].Label)
+	require.Error(t, results[3].Err)
+	require.Equal(t, "id", results[3].Label)
+
+	// The outputs are looked up by label
+	out, err := multi.Bin2Str("id", id)
+	require.NoError(t, err)
+	require.Equal(t, "0188f83a-752a-229f-5e14-b8052b4be0de", out)
+
+	_, err = multi.Bin2Str("uuid", id)
+	require.EqualError(t, err, `no pipeline named "uuid"`)
+}
+
+func TestMultiPipelineRepeatedSteps(t *testing.T) {
+	// A repeated decoder specification replaces the earlier output in its position
+	multi, err := binutil.NewMulti("hex", "b64", "hex")
+	require.NoError(t, err)
+	require.Equal(t, []string{"hex", "b64"}, multi.Labels())
+
+	results := multi.BinToAll([]byte{0xde, 0xad})
+	require.Equal(t, []binutil.Result{{Label: "hex", Output: "dead"}, {Label: "b64", Output: "3q0="}}, results)
+
+	out, err := multi.Bin2Str("hex", []byte{0xde, 0xad})
+	require.NoError(t, err)
+	require.Equal(t, "dead", out)
+
+	_, err = binutil.NewMulti("hex", "foo")
+	require.Error(t, err)
+}
+
+func TestMultiPipelineStrToAll(t *testing.T) {
+	multi, err := binutil.NewMultiOutputs([]binutil.Output{
+		{Label: "upper", Steps: []any{"hex", "b64"}},
+		{Label: "url", Steps: []any{"hex", "b64url"}},
+		{Label: "id", Steps: []any{"hex", "uuid"}},
+	})
+	require.NoError(t, err)
+
+	results := multi.StrToAll("fbff")
+	require.Len(t, results, 3)
+	require.Equal(t, binutil.Result{Label: "upper", Output: "+/8="}, results[0])
+	require.Equal(t, binutil.Result{Label: "url", Output: "-_8="}, results[1])
+	require.Equal(t, "id", results[2].Label)
+	require.Error(t, results[2].Err)
+
+	// An empty multi pipeline has no results
+	multi, err = binutil.NewMultiOutputs(nil)
+	require.NoError(t, err)
+	require.Empty(t, multi.StrToAll("fbff"))
+
+	multi, err = binutil.NewMulti()
+	require.NoError(t, err)
+	require.Empty(t, multi.StrToAll("fbff"))
+}
+
+func TestNewMultiOutputsErrors(t *testing.T) {
+	_, err := binutil.NewMultiOutputs([]binutil.Output{{Label: "hex", Steps: []any{"hex"}}, {Label: "hex", Steps: []any{"b64"}}})
+	require.ErrorIs(t, err, binutil.ErrDuplicateOutput)
+
+	_, err = binutil.NewMultiOutputs([]binutil.Output{{Steps: []any{"b64"}}})
+	require.ErrorIs(t, err, binutil.ErrNoOutputLabel)
+
+	_, err = binutil.NewMultiOutputs([]binutil.Output{{Label: "id", Steps: []any{"ulid", "foo"}}})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `output "id"`)
+
+	// Options of an output override the options of the multi pipeline
+	multi, err := binutil.NewMultiOutputs(
+		[]binutil.Output{
+			{Label: "hex", Steps: []any{"hex"}},
+			{Label: "big", Steps: []any{"hex", binutil.WithLimits(binutil.Limits{})}},
+		},
+		binutil.WithLimits(binutil.Limits{MaxInput: 2}),
+	)
+	require.NoError(t, err)
+
+	results := multi.BinToAll([]byte{1, 2, 3})
+	require.ErrorIs(t, results[0].Err, binutil.ErrInputTooLarge)
+	require.NoError(t, results[1].Err)
+	require.Equal(t, "010203", results[1].Output)
+}
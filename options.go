@@ -0,0 +1,125 @@
+package binutil
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Options are the key/value parameters parsed from a step specification such as
+// "pem:type=CERTIFICATE,index=1". Keys are case insensitive and a key without a value
+// is treated as a boolean flag and stored with the value "true".
+type Options map[string]string
+
+// OptionsConstructor creates a new Decoder that is configured by the options parsed
+// from the step specification. It should return an error if the options are invalid.
+type OptionsConstructor func(opts Options) (Decoder, error)
+
+// ParseStep splits a step specification into the decoder name and its options. The
+// name is separated from the options by the first colon and options are separated from
+// each other by commas, e.g. "aesgcm:key=env:MY_KEY,nonce=suffix".
+func ParseStep(spec string) (name string, opts Options, err error) {
+	var params string
+	name, params, _ = strings.Cut(spec, ":")
+	name = strings.TrimSpace(strings.ToLower(name))
+
+	opts = make(Options)
+	if params = strings.TrimSpace(params); params == "" {
+		return name, opts, nil
+	}
+
+	for _, param := range strings.Split(params, ",") {
+		key, val, hasVal := strings.Cut(param, "=")
+		key = strings.TrimSpace(strings.ToLower(key))
+		if key == "" {
+			return "", nil, fmt.Errorf("invalid option %q in step %q", param, spec)
+		}
+
+		if !hasVal {
+			val = "true"
+		}
+		opts[key] = strings.TrimSpace(val)
+	}
+	return name, opts, nil
+}
+
+// Get returns the value of the option or the default value if it was not specified.
+func (o Options) Get(key, def string) string {
+	if val, ok := o[key]; ok {
+		return val
+	}
+	return def
+}
+
+// Bool returns true if the option was specified as a flag or with a true value.
+func (o Options) Bool(key string) (_ bool, err error) {
+	val, ok := o[key]
+	if !ok {
+		return false, nil
+	}
+
+	var flag bool
+	if flag, err = strconv.ParseBool(val); err != nil {
+		return false, fmt.Errorf("option %q must be a boolean value", key)
+	}
+	return flag, nil
+}
+
+// Int returns the integer value of the option or the default value if not specified.
+func (o Options) Int(key string, def int) (_ int, err error) {
+	val, ok := o[key]
+	if !ok {
+		return def, nil
+	}
+
+	var num int
+	if num, err = strconv.Atoi(val); err != nil {
+		return 0, fmt.Errorf("option %q must be an integer value", key)
+	}
+	return num, nil
+}
+
+// Bytes resolves the option value to binary data so that secrets do not have to be
+// passed directly on the command line. Values prefixed with "env:" are read from the
+// environment, "file:" from a file on disk, "hex:" and "b64:" are decoded from their
+// respective encodings; any other value is used as literal UTF-8 bytes. Returns nil if
+// the option was not specified.
+func (o Options) Bytes(key string) (_ []byte, err error) {
+	val, ok := o[key]
+	if !ok {
+		return nil, nil
+	}
+
+	prefix, ref, found := strings.Cut(val, ":")
+	if !found {
+		return []byte(val), nil
+	}
+
+	var data []byte
+	switch strings.ToLower(prefix) {
+	case "env":
+		var ok bool
+		if val, ok = os.LookupEnv(ref); !ok {
+			return nil, fmt.Errorf("option %q: environment variable %q is not set", key, ref)
+		}
+		data = []byte(val)
+	case "file":
+		if data, err = os.ReadFile(ref); err != nil {
+			return nil, fmt.Errorf("option %q: %w", key, err)
+		}
+	case "hex":
+		if data, err = hex.DecodeString(ref); err != nil {
+			return nil, fmt.Errorf("option %q: %w", key, err)
+		}
+	case "b64", "base64":
+		if data, err = base64.StdEncoding.DecodeString(ref); err != nil {
+			return nil, fmt.Errorf("option %q: %w", key, err)
+		}
+	default:
+		data = []byte(val)
+	}
+	return data, nil
+}
@@ -0,0 +1,83 @@
+package binutil_test
+
+import (
+	"testing"
+
+	"github.com/bbengfort/binutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStep(t *testing.T) {
+	testCases := []struct {
+		spec string
+		name string
+		opts binutil.Options
+	}{
+		{"hex", "hex", binutil.Options{}},
+		{" Base64 ", "base64", binutil.Options{}},
+		{"pem:type=CERTIFICATE,index=1", "pem", binutil.Options{"type": "CERTIFICATE", "index": "1"}},
+		{"aesgcm:key=env:DATA_KEY,nonce=suffix", "aesgcm", binutil.Options{"key": "env:DATA_KEY", "nonce": "suffix"}},
+		{"json:pretty,sorted", "json", binutil.Options{"pretty": "true", "sorted": "true"}},
+		{"b64:", "b64", binutil.Options{}},
+	}
+
+	for i, tc := range testCases {
+		name, opts, err := binutil.ParseStep(tc.spec)
+		require.NoError(t, err, "could not parse step for test case %d", i)
+		require.Equal(t, tc.name, name, "unexpected name for test case %d", i)
+		require.Equal(t, tc.opts, opts, "unexpected options for test case %d", i)
+	}
+
+	_, _, err := binutil.ParseStep("pem:=CERTIFICATE")
+	require.Error(t, err, "expected an empty option key to error")
+}
+
+func TestOptions(t *testing.T) {
+	t.Setenv("BINUTIL_TEST_OPTION", "from the environment")
+	opts := binutil.Options{
+		"flag": "true",
+		"num":  "42",
+		"bad":  "forty-two",
+		"env":  "env:BINUTIL_TEST_OPTION",
+		"hex":  "hex:68656c6c6f",
+		"b64":  "b64:aGVsbG8=",
+		"lit":  "hello",
+	}
+
+	flag, err := opts.Bool("flag")
+	require.NoError(t, err)
+	require.True(t, flag)
+
+	flag, err = opts.Bool("missing")
+	require.NoError(t, err)
+	require.False(t, flag)
+
+	num, err := opts.Int("num", 0)
+	require.NoError(t, err)
+	require.Equal(t, 42, num)
+
+	num, err = opts.Int("missing", 7)
+	require.NoError(t, err)
+	require.Equal(t, 7, num)
+
+	_, err = opts.Int("bad", 0)
+	require.Error(t, err)
+
+	for key, expected := range map[string]string{"env": "from the environment", "hex": "hello", "b64": "hello", "lit": "hello"} {
+		data, err := opts.Bytes(key)
+		require.NoError(t, err, "could not resolve bytes for option %q", key)
+		require.Equal(t, expected, string(data), "unexpected bytes for option %q", key)
+	}
+
+	data, err := opts.Bytes("missing")
+	require.NoError(t, err)
+	require.Nil(t, data)
+
+	_, err = binutil.Options{"key": "env:BINUTIL_TEST_UNSET_VARIABLE"}.Bytes("key")
+	require.Error(t, err, "expected an unset environment variable to error")
+}
+
+func TestDecoderWithoutOptions(t *testing.T) {
+	_, err := binutil.NewDecoder("hex:upper")
+	require.EqualError(t, err, "decoder \"hex\" does not accept options")
+}
@@ -0,0 +1,144 @@
+package binutil
+
+import (
+	"bytes"
+	"encoding/pem"
+	"strings"
+)
+
+func init() {
+	RegisterOptionsDecoder(PEMDecoder, func(opts Options) (Decoder, error) { return NewPEMFromOptions(opts) })
+	DescribeDecoder(PEMDecoder, Info{Description: "PEM armored blocks", Category: RadixCategory, Capabilities: Bidirectional, Options: []string{"type", "index"}})
+}
+
+const (
+	PEMDecoder          = "pem"
+	DefaultPEMBlockType = "DATA"
+)
+
+var pemPreamble = []byte("-----BEGIN ")
+
+// NewPEM returns a PEM step that encodes binary data into blocks of the specified type.
+// If the type is empty then all blocks are selected when decoding and binary data is
+// encoded using the default block type.
+func NewPEM(blockType string) *PEM {
+	return &PEM{Type: blockType, Index: -1}
+}
+
+// NewPEMFromOptions creates a PEM step from the step specification options. The type
+// option filters blocks by type when decoding and sets the block type when encoding.
+// The index option selects a single block (zero indexed) from the blocks that match the
+// type filter; by default all matching blocks are selected.
+func NewPEMFromOptions(opts Options) (_ *PEM, err error) {
+	p := NewPEM(opts.Get("type", ""))
+	if p.Index, err = opts.Int("index", -1); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// PEM implements the encoder and decoder interface for PEM armored data as produced by
+// openssl and other tools. The string representation is one or more PEM blocks and the
+// binary representation is the DER bytes of the selected blocks concatenated together;
+// for example a certificate chain becomes a sequence of DER certificates.
+//
+// When encoding binary data as PEM the Type and Headers fields are used to create the
+// block; encoded blocks are wrapped at 64 columns.
+type PEM struct {
+	Type    string
+	Index   int
+	Headers map[string]string
+	blocks  []*pem.Block
+}
+
+var (
+	_ Encoder = &PEM{}
+	_ Decoder = &PEM{}
+)
+
+// DecodeBinary wraps the DER bytes in a PEM block ready to be encoded. If the input
+// starts with a PEM block (after any leading whitespace) then it is decoded as a string
+// instead so that files read from disk can be passed in directly; binary data that only
+// contains the preamble elsewhere is wrapped like any other data.
+func (p PEM) DecodeBinary(in []byte) (Encoder, error) {
+	if text := bytes.TrimLeft(in, " \t\r\n"); bytes.HasPrefix(text, pemPreamble) {
+		return p.DecodeString(string(text))
+	}
+
+	blockType := p.Type
+	if blockType == "" {
+		blockType = DefaultPEMBlockType
+	}
+
+	block := &pem.Block{Type: blockType, Headers: p.Headers, Bytes: in}
+	return &PEM{Type: p.Type, Index: p.Index, Headers: p.Headers, blocks: []*pem.Block{block}}, nil
+}
+
+// DecodeString parses all of the PEM blocks in the input, ignoring any text between the
+// blocks, then selects the blocks that match the type and index filters.
+func (p PEM) DecodeString(in string) (_ Encoder, err error) {
+	var (
+		block  *pem.Block
+		blocks []*pem.Block
+	)
+
+	rest := []byte(in)
+	for {
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+
+		if p.Type == "" || strings.EqualFold(p.Type, block.Type) {
+			blocks = append(blocks, block)
+		}
+	}
+
+	if p.Index >= 0 {
+		if p.Index >= len(blocks) {
+			return nil, ErrNoPEMBlocks
+		}
+		blocks = blocks[p.Index : p.Index+1]
+	}
+
+	if len(blocks) == 0 {
+		return nil, ErrNoPEMBlocks
+	}
+	return &PEM{Type: p.Type, Index: p.Index, Headers: p.Headers, blocks: blocks}, nil
+}
+
+// EncodeBinary returns the DER bytes of the selected blocks.
+func (p PEM) EncodeBinary() ([]byte, error) {
+	if len(p.blocks) == 0 {
+		return nil, ErrNoData
+	}
+
+	if len(p.blocks) == 1 {
+		return p.blocks[0].Bytes, nil
+	}
+
+	var out []byte
+	for _, block := range p.blocks {
+		out = append(out, block.Bytes...)
+	}
+	return out, nil
+}
+
+// EncodeString armors the selected blocks, including any block headers.
+func (p PEM) EncodeString() (_ string, err error) {
+	if len(p.blocks) == 0 {
+		return "", ErrNoData
+	}
+
+	var out strings.Builder
+	for _, block := range p.blocks {
+		if err = pem.Encode(&out, block); err != nil {
+			return "", err
+		}
+	}
+	return out.String(), nil
+}
+
+// Blocks returns the selected PEM blocks, including their types and headers.
+func (p PEM) Blocks() []*pem.Block {
+	return p.blocks
+}
@@ -0,0 +1,135 @@
+package binutil_test
+
+import (
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/bbengfort/binutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPEM(t *testing.T) {
+	p := binutil.NewPEM("TEST DATA")
+	for _, fixture := range fixtures() {
+		eb, err := p.DecodeBinary(fixture.data)
+		require.NoError(t, err, "could not decode binary for fixture %q", fixture.name)
+
+		s, err := eb.EncodeString()
+		require.NoError(t, err, "could not encode string for fixture %q", fixture.name)
+		require.True(t, strings.HasPrefix(s, "-----BEGIN TEST DATA-----\n"), "expected pem block type for fixture %q", fixture.name)
+
+		for _, line := range strings.Split(s, "\n") {
+			require.LessOrEqual(t, len(line), 64, "expected pem to be wrapped at 64 columns for fixture %q", fixture.name)
+		}
+
+		es, err := p.DecodeString(s)
+		require.NoError(t, err, "could not decode string for fixture %q", fixture.name)
+
+		data, err := es.EncodeBinary()
+		require.NoError(t, err, "could not encode binary for fixture %q", fixture.name)
+		require.Equal(t, len(fixture.data), len(data), "expected unchanged binary data for fixture %q", fixture.name)
+		if len(fixture.data) > 0 {
+			require.Equal(t, fixture.data, data, "expected unchanged binary data for fixture %q", fixture.name)
+		}
+	}
+}
+
+func TestPEMMultiBlock(t *testing.T) {
+	blocks := []*pem.Block{
+		{Type: "CERTIFICATE", Bytes: []byte{0x01, 0x01}},
+		{Type: "PRIVATE KEY", Bytes: []byte{0x02, 0x02}, Headers: map[string]string{"Proc-Type": "4,ENCRYPTED"}},
+		{Type: "CERTIFICATE", Bytes: []byte{0x03, 0x03}},
+	}
+
+	var sb strings.Builder
+	sb.WriteString("subject=CN = example.com\n")
+	for _, block := range blocks {
+		require.NoError(t, pem.Encode(&sb, block))
+		sb.WriteString("some openssl chatter\n")
+	}
+	in := sb.String()
+
+	testCases := []struct {
+		spec     string
+		expected []byte
+		types    []string
+	}{
+		{"pem", []byte{1, 1, 2, 2, 3, 3}, []string{"CERTIFICATE", "PRIVATE KEY", "CERTIFICATE"}},
+		{"pem:type=CERTIFICATE", []byte{1, 1, 3, 3}, []string{"CERTIFICATE", "CERTIFICATE"}},
+		{"pem:type=certificate,index=1", []byte{3, 3}, []string{"CERTIFICATE"}},
+		{"pem:index=1", []byte{2, 2}, []string{"PRIVATE KEY"}},
+	}
+
+	for i, tc := range testCases {
+		dec, err := binutil.NewDecoder(tc.spec)
+		require.NoError(t, err, "could not create decoder for test case %d", i)
+
+		enc, err := dec.DecodeString(in)
+		require.NoError(t, err, "could not decode pem for test case %d", i)
+
+		data, err := enc.EncodeBinary()
+		require.NoError(t, err, "could not encode binary for test case %d", i)
+		require.Equal(t, tc.expected, data, "unexpected der bytes for test case %d", i)
+
+		selected := enc.(*binutil.PEM).Blocks()
+		require.Len(t, selected, len(tc.types), "unexpected number of blocks for test case %d", i)
+		for j, block := range selected {
+			require.Equal(t, tc.types[j], block.Type, "unexpected block type for test case %d", i)
+		}
+	}
+
+	// Headers must be preserved when re-encoding the blocks
+	dec, err := binutil.NewDecoder("pem:type=PRIVATE KEY")
+	require.NoError(t, err)
+	enc, err := dec.DecodeString(in)
+	require.NoError(t, err)
+	out, err := enc.EncodeString()
+	require.NoError(t, err)
+	require.Contains(t, out, "Proc-Type: 4,ENCRYPTED")
+
+	// Filters that do not match any block should error
+	for _, spec := range []string{"pem:type=CERTIFICATE REQUEST", "pem:index=3"} {
+		dec, err := binutil.NewDecoder(spec)
+		require.NoError(t, err)
+		_, err = dec.DecodeString(in)
+		require.ErrorIs(t, err, binutil.ErrNoPEMBlocks, "expected no blocks for %q", spec)
+	}
+
+	// PEM text passed as binary data should be parsed rather than wrapped
+	dec, err = binutil.NewDecoder("pem:type=CERTIFICATE,index=0")
+	require.NoError(t, err)
+	armored := "\n  " + in[strings.Index(in, "-----BEGIN "):]
+	enc, err = dec.DecodeBinary([]byte(armored))
+	require.NoError(t, err)
+	data, err := enc.EncodeBinary()
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 1}, data)
+
+	// Binary data that only contains the preamble after other bytes is wrapped
+	der := append([]byte{0x30, 0x82}, []byte(in)...)
+	enc, err = dec.DecodeBinary(der)
+	require.NoError(t, err)
+	data, err = enc.EncodeBinary()
+	require.NoError(t, err)
+	require.Equal(t, der, data)
+}
+
+func TestPEMPipeline(t *testing.T) {
+	pipe, err := binutil.New("hex", "pem:type=PUBLIC KEY")
+	require.NoError(t, err)
+
+	out, err := pipe.Str2Str("0a0b0c0d")
+	require.NoError(t, err)
+	require.Equal(t, "-----BEGIN PUBLIC KEY-----\nCgsMDQ==\n-----END PUBLIC KEY-----\n", out)
+
+	pipe, err = binutil.New("pem", "hex")
+	require.NoError(t, err)
+
+	out, err = pipe.Str2Str("-----BEGIN PUBLIC KEY-----\nCgsMDQ==\n-----END PUBLIC KEY-----\n")
+	require.NoError(t, err)
+	require.Equal(t, "0a0b0c0d", out)
+
+	_, err = binutil.NewDecoder("pem:index=first")
+	require.Error(t, err, "expected a non-integer index to error")
+}
@@ -0,0 +1,198 @@
+package binutil
+
+import (
+	"fmt"
+	"os"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func init() {
+	RegisterOptionsDecoder(ProtobufDecoder, func(opts Options) (Decoder, error) { return NewProtobufFromOptions(opts) }, "proto")
+	DescribeDecoder(ProtobufDecoder, Info{Description: "protocol buffer messages as JSON using a descriptor set", Category: StructuredCategory, Capabilities: Bidirectional | Lossy, Options: []string{"desc", "type", "compact", "names", "defaults"}})
+}
+
+const ProtobufDecoder = "protobuf"
+
+// NewProtobuf returns a Protobuf step for the message type with the specified
+// descriptor; the resolver is used to look up the types in google.protobuf.Any fields
+// and may be nil to use the global registry.
+func NewProtobuf(desc protoreflect.MessageDescriptor, resolver *dynamicpb.Types) *Protobuf {
+	p := &Protobuf{Descriptor: desc}
+	p.Marshal.Multiline = true
+	if resolver != nil {
+		p.Marshal.Resolver = resolver
+		p.Unmarshal.Resolver = resolver
+	}
+	return p
+}
+
+// NewProtobufFromOptions creates a Protobuf step from the step specification options.
+// The desc option is the path to a serialized FileDescriptorSet (e.g. created with
+// protoc --descriptor_set_out=api.binpb --include_imports or buf build -o api.binpb)
+// and the type option is the full name of the message, e.g. pkg.Msg. The descriptor set
+// is loaded once when the step is created. The compact flag renders JSON on a single
+// line, the names flag uses the proto field names rather than lowerCamelCase, and the
+// defaults flag includes fields that are not populated.
+func NewProtobufFromOptions(opts Options) (_ *Protobuf, err error) {
+	path, name := opts.Get("desc", ""), opts.Get("type", "")
+	if path == "" || name == "" {
+		return nil, ErrNoDescriptor
+	}
+
+	var (
+		files *protoregistry.Files
+		desc  protoreflect.MessageDescriptor
+	)
+
+	if files, err = LoadDescriptorSet(path); err != nil {
+		return nil, err
+	}
+
+	if desc, err = findMessageDescriptor(files, name); err != nil {
+		return nil, err
+	}
+
+	p := NewProtobuf(desc, dynamicpb.NewTypes(files))
+
+	var compact bool
+	if compact, err = opts.Bool("compact"); err != nil {
+		return nil, err
+	}
+	p.Marshal.Multiline = !compact
+
+	if p.Marshal.UseProtoNames, err = opts.Bool("names"); err != nil {
+		return nil, err
+	}
+
+	if p.Marshal.EmitUnpopulated, err = opts.Bool("defaults"); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// LoadDescriptorSet reads a serialized FileDescriptorSet from disk. The set must contain
+// all of the imported files (other than the well-known types linked into the binary).
+func LoadDescriptorSet(path string) (_ *protoregistry.Files, err error) {
+	var data []byte
+	if data, err = os.ReadFile(path); err != nil {
+		return nil, err
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	if err = proto.Unmarshal(data, set); err != nil {
+		return nil, fmt.Errorf("could not parse descriptor set %q: %w", path, err)
+	}
+
+	var files *protoregistry.Files
+	if files, err = protodesc.NewFiles(set); err != nil {
+		return nil, fmt.Errorf("could not load descriptor set %q: %w", path, err)
+	}
+	return files, nil
+}
+
+func findMessageDescriptor(files *protoregistry.Files, name string) (protoreflect.MessageDescriptor, error) {
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, fmt.Errorf("could not find message type %q: %w", name, err)
+	}
+
+	msg, ok := desc.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a message type", name)
+	}
+	return msg, nil
+}
+
+// Protobuf implements the encoder and decoder interface for protocol buffer messages
+// with a known schema. The binary representation is the protobuf wire format and the
+// string representation is protojson, so the step can convert binary messages into
+// typed JSON and JSON fixtures back into binary messages.
+type Protobuf struct {
+	Descriptor protoreflect.MessageDescriptor
+	Marshal    protojson.MarshalOptions
+	Unmarshal  protojson.UnmarshalOptions
+	msg        proto.Message
+}
+
+var (
+	_ Encoder = &Protobuf{}
+	_ Decoder = &Protobuf{}
+)
+
+// DecodeBinary unmarshals the wire format into a message of the descriptor type.
+func (p Protobuf) DecodeBinary(in []byte) (_ Encoder, err error) {
+	if p.Descriptor == nil {
+		return nil, ErrNoDescriptor
+	}
+
+	out := p.clone()
+	if err = (proto.UnmarshalOptions{Resolver: p.resolver()}).Unmarshal(in, out.msg); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DecodeString unmarshals protojson into a message of the descriptor type.
+func (p Protobuf) DecodeString(in string) (_ Encoder, err error) {
+	if p.Descriptor == nil {
+		return nil, ErrNoDescriptor
+	}
+
+	out := p.clone()
+	if err = p.Unmarshal.Unmarshal([]byte(in), out.msg); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EncodeBinary deterministically marshals the message into the wire format.
+func (p Protobuf) EncodeBinary() ([]byte, error) {
+	if p.msg == nil {
+		return nil, ErrNoData
+	}
+	return proto.MarshalOptions{Deterministic: true}.Marshal(p.msg)
+}
+
+// EncodeString marshals the message as protojson.
+func (p Protobuf) EncodeString() (string, error) {
+	if p.msg == nil {
+		return "", ErrNoData
+	}
+
+	data, err := p.Marshal.Marshal(p.msg)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Message returns the decoded message.
+func (p Protobuf) Message() proto.Message {
+	return p.msg
+}
+
+func (p Protobuf) clone() *Protobuf {
+	return &Protobuf{
+		Descriptor: p.Descriptor,
+		Marshal:    p.Marshal,
+		Unmarshal:  p.Unmarshal,
+		msg:        dynamicpb.NewMessage(p.Descriptor),
+	}
+}
+
+func (p Protobuf) resolver() interface {
+	protoregistry.ExtensionTypeResolver
+	protoregistry.MessageTypeResolver
+} {
+	if types, ok := p.Unmarshal.Resolver.(*dynamicpb.Types); ok {
+		return types
+	}
+	return protoregistry.GlobalTypes
+}
@@ -0,0 +1,113 @@
+package binutil_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bbengfort/binutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestProtobuf(t *testing.T) {
+	path := descriptorSet(t)
+
+	encode, err := binutil.New("protobuf:desc="+path+",type=binutil.test.Person", "b64")
+	require.NoError(t, err, "could not create json to binary pipeline")
+
+	decode, err := binutil.New("b64", "protobuf:desc="+path+",type=binutil.test.Person,compact")
+	require.NoError(t, err, "could not create binary to json pipeline")
+
+	fixture := `{"name":"Jane Doe","id":"42","role":"ROLE_ADMIN","emails":["jane@example.com","jd@example.com"],"address":{"city":"Springfield"}}`
+	b64, err := encode.Str2Str(fixture)
+	require.NoError(t, err, "could not encode json as a binary message")
+
+	out, err := decode.Str2Str(b64)
+	require.NoError(t, err, "could not decode binary message as json")
+	require.JSONEq(t, fixture, out)
+
+	// The binary message should be readable by the schemaless decoder
+	raw, err := binutil.New("b64", "protowire")
+	require.NoError(t, err)
+	dump, err := raw.Str2Str(b64)
+	require.NoError(t, err)
+	require.Contains(t, dump, `1 bytes: "Jane Doe"`)
+	require.Contains(t, dump, `2 varint: 42`)
+
+	// Field name and default options
+	names, err := binutil.New("b64", "protobuf:desc="+path+",type=binutil.test.Person,compact,names,defaults")
+	require.NoError(t, err)
+	b64, err = encode.Str2Str(`{"name":"John"}`)
+	require.NoError(t, err)
+	out, err = names.Str2Str(b64)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name":"John","id":"0","role":"ROLE_UNKNOWN","emails":[],"address":null}`, out)
+
+	_, err = encode.Str2Str(`{"unknown_field":true}`)
+	require.Error(t, err, "expected unknown json fields to error")
+}
+
+func TestProtobufOptions(t *testing.T) {
+	path := descriptorSet(t)
+
+	_, err := binutil.NewDecoder("protobuf")
+	require.ErrorIs(t, err, binutil.ErrNoDescriptor)
+
+	_, err = binutil.NewDecoder("protobuf:desc=" + path)
+	require.ErrorIs(t, err, binutil.ErrNoDescriptor)
+
+	_, err = binutil.NewDecoder("protobuf:desc=" + path + ",type=binutil.test.Missing")
+	require.Error(t, err, "expected an unknown message type to error")
+
+	_, err = binutil.NewDecoder("protobuf:desc=" + path + ",type=binutil.test.Role")
+	require.Error(t, err, "expected an enum type to error")
+
+	_, err = binutil.NewDecoder("protobuf:desc=" + filepath.Join(t.TempDir(), "missing.binpb") + ",type=binutil.test.Person")
+	require.Error(t, err, "expected a missing descriptor set to error")
+}
+
+// Write a descriptor set for a small test schema to a temporary file.
+func descriptorSet(t *testing.T) string {
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	repeated := descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("binutil/test/person.proto"),
+		Package: proto.String("binutil.test"),
+		Syntax:  proto.String("proto3"),
+		EnumType: []*descriptorpb.EnumDescriptorProto{{
+			Name: proto.String("Role"),
+			Value: []*descriptorpb.EnumValueDescriptorProto{
+				{Name: proto.String("ROLE_UNKNOWN"), Number: proto.Int32(0)},
+				{Name: proto.String("ROLE_ADMIN"), Number: proto.Int32(1)},
+			},
+		}},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Address"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("city"), JsonName: proto.String("city"), Number: proto.Int32(1), Label: optional, Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+				},
+			},
+			{
+				Name: proto.String("Person"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("name"), JsonName: proto.String("name"), Number: proto.Int32(1), Label: optional, Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+					{Name: proto.String("id"), JsonName: proto.String("id"), Number: proto.Int32(2), Label: optional, Type: descriptorpb.FieldDescriptorProto_TYPE_INT64.Enum()},
+					{Name: proto.String("role"), JsonName: proto.String("role"), Number: proto.Int32(3), Label: optional, Type: descriptorpb.FieldDescriptorProto_TYPE_ENUM.Enum(), TypeName: proto.String(".binutil.test.Role")},
+					{Name: proto.String("emails"), JsonName: proto.String("emails"), Number: proto.Int32(4), Label: repeated, Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+					{Name: proto.String("address"), JsonName: proto.String("address"), Number: proto.Int32(5), Label: optional, Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: proto.String(".binutil.test.Address")},
+				},
+			},
+		},
+	}
+
+	data, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}})
+	require.NoError(t, err, "could not marshal descriptor set")
+
+	path := filepath.Join(t.TempDir(), "person.binpb")
+	require.NoError(t, os.WriteFile(path, data, 0644), "could not write descriptor set")
+	return path
+}
@@ -0,0 +1,400 @@
+package binutil
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func init() {
+	RegisterDecoder(ProtoWireDecoder, func() Decoder { return &ProtoWire{} }, "protoraw", "decode-raw")
+	DescribeDecoder(ProtoWireDecoder, Info{Description: "schemaless dump of the protocol buffer wire format", Category: StructuredCategory, Capabilities: Bidirectional})
+}
+
+const ProtoWireDecoder = "protowire"
+
+// Nested length-delimited fields deeper than this are rendered as bytes.
+const maxProtoWireDepth = 64
+
+// ProtoWire implements the encoder and decoder interface for protocol buffers without a
+// schema. The binary representation is the protobuf wire format and the string
+// representation is a dump of the fields similar to protoc --decode_raw, one field per
+// line with the field number, wire type, and value:
+//
+//	1 varint: 150 (int64: 150, zigzag: 75)
+//	2 bytes: "hello world"
+//	3 message {
+//	  1 fixed32: 0x3f800000 (int: 1065353216, float: 1)
+//	}
+//
+// Varints are rendered unsigned with their signed and zigzag interpretations, fixed
+// width values as hex with their integer and floating point interpretations, and
+// length-delimited fields as quoted strings if they are printable text, as nested
+// messages if they parse as protobuf, and otherwise as hex. The parenthesized
+// interpretations are ignored when the dump is decoded back into the wire format.
+type ProtoWire struct {
+	data []byte
+}
+
+var (
+	_ Encoder = &ProtoWire{}
+	_ Decoder = &ProtoWire{}
+)
+
+// DecodeBinary ensures the input is a well formed protobuf message and wraps it.
+func (p ProtoWire) DecodeBinary(in []byte) (_ Encoder, err error) {
+	if _, err = parseWireFields(in, 0); err != nil {
+		return nil, err
+	}
+	return &ProtoWire{data: in}, nil
+}
+
+// DecodeString parses the field dump and builds the wire format from it.
+func (p ProtoWire) DecodeString(in string) (_ Encoder, err error) {
+	var fields []*wireField
+	if fields, err = parseWireDump(in); err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, 0)
+	for _, field := range fields {
+		data = field.append(data)
+	}
+	return &ProtoWire{data: data}, nil
+}
+
+// EncodeBinary returns the protobuf wire format data.
+func (p ProtoWire) EncodeBinary() ([]byte, error) {
+	if p.data != nil {
+		return p.data, nil
+	}
+	return nil, ErrNoData
+}
+
+// EncodeString renders the wire format data as a field dump.
+func (p ProtoWire) EncodeString() (_ string, err error) {
+	if p.data == nil {
+		return "", ErrNoData
+	}
+
+	var fields []*wireField
+	if fields, err = parseWireFields(p.data, 0); err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	for _, field := range fields {
+		field.dump(&out, 0)
+	}
+	return out.String(), nil
+}
+
+// Wire type names used in the field dump; length-delimited fields are either bytes or
+// a nested message and start groups contain nested fields until the end group.
+const (
+	wireVarint  = "varint"
+	wireFixed32 = "fixed32"
+	wireFixed64 = "fixed64"
+	wireBytes   = "bytes"
+	wireMessage = "message"
+	wireGroup   = "group"
+)
+
+type wireField struct {
+	line     int
+	num      protowire.Number
+	kind     string
+	value    uint64
+	bytes    []byte
+	children []*wireField
+}
+
+// Parse the wire format into fields, attempting to parse length-delimited fields that
+// are not printable text as nested messages.
+func parseWireFields(b []byte, depth int) (fields []*wireField, err error) {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		field := &wireField{num: num}
+		switch typ {
+		case protowire.VarintType:
+			field.kind = wireVarint
+			field.value, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed32Type:
+			var v uint32
+			field.kind = wireFixed32
+			v, n = protowire.ConsumeFixed32(b)
+			field.value = uint64(v)
+		case protowire.Fixed64Type:
+			field.kind = wireFixed64
+			field.value, n = protowire.ConsumeFixed64(b)
+		case protowire.BytesType:
+			field.kind = wireBytes
+			if field.bytes, n = protowire.ConsumeBytes(b); n >= 0 && depth < maxProtoWireDepth && !printable(field.bytes) {
+				if children, err := parseWireFields(field.bytes, depth+1); err == nil && len(children) > 0 {
+					field.kind, field.children = wireMessage, children
+				}
+			}
+		case protowire.StartGroupType:
+			var group []byte
+			field.kind = wireGroup
+			if group, n = protowire.ConsumeGroup(num, b); n >= 0 {
+				if depth >= maxProtoWireDepth {
+					return nil, fmt.Errorf("protowire: groups nested too deeply")
+				}
+
+				if field.children, err = parseWireFields(group, depth+1); err != nil {
+					return nil, err
+				}
+			}
+		default:
+			return nil, fmt.Errorf("protowire: unexpected wire type %d for field %d", typ, num)
+		}
+
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// Length-delimited fields are rendered as strings if they are valid UTF-8 without any
+// control characters other than whitespace.
+func printable(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) && r != '\n' && r != '\r' && r != '\t' {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *wireField) dump(out *strings.Builder, depth int) {
+	indent := strings.Repeat("  ", depth)
+	switch f.kind {
+	case wireVarint:
+		fmt.Fprintf(out, "%s%d varint: %d (int64: %d, zigzag: %d)\n", indent, f.num, f.value, int64(f.value), protowire.DecodeZigZag(f.value))
+	case wireFixed32:
+		fmt.Fprintf(out, "%s%d fixed32: 0x%08x (int: %d, float: %v)\n", indent, f.num, f.value, int32(f.value), math.Float32frombits(uint32(f.value)))
+	case wireFixed64:
+		fmt.Fprintf(out, "%s%d fixed64: 0x%016x (int: %d, double: %v)\n", indent, f.num, f.value, int64(f.value), math.Float64frombits(f.value))
+	case wireBytes:
+		if printable(f.bytes) {
+			fmt.Fprintf(out, "%s%d bytes: %s\n", indent, f.num, strconv.Quote(string(f.bytes)))
+		} else {
+			fmt.Fprintf(out, "%s%d bytes: %x\n", indent, f.num, f.bytes)
+		}
+	case wireMessage, wireGroup:
+		fmt.Fprintf(out, "%s%d %s {\n", indent, f.num, f.kind)
+		for _, child := range f.children {
+			child.dump(out, depth+1)
+		}
+		fmt.Fprintf(out, "%s}\n", indent)
+	}
+}
+
+func (f *wireField) append(b []byte) []byte {
+	switch f.kind {
+	case wireVarint:
+		b = protowire.AppendTag(b, f.num, protowire.VarintType)
+		return protowire.AppendVarint(b, f.value)
+	case wireFixed32:
+		b = protowire.AppendTag(b, f.num, protowire.Fixed32Type)
+		return protowire.AppendFixed32(b, uint32(f.value))
+	case wireFixed64:
+		b = protowire.AppendTag(b, f.num, protowire.Fixed64Type)
+		return protowire.AppendFixed64(b, f.value)
+	case wireBytes:
+		b = protowire.AppendTag(b, f.num, protowire.BytesType)
+		return protowire.AppendBytes(b, f.bytes)
+	case wireMessage:
+		var msg []byte
+		for _, child := range f.children {
+			msg = child.append(msg)
+		}
+		b = protowire.AppendTag(b, f.num, protowire.BytesType)
+		return protowire.AppendBytes(b, msg)
+	case wireGroup:
+		b = protowire.AppendTag(b, f.num, protowire.StartGroupType)
+		for _, child := range f.children {
+			b = child.append(b)
+		}
+		return protowire.AppendTag(b, f.num, protowire.EndGroupType)
+	}
+	return b
+}
+
+// Parse the field dump into fields; nested messages and groups are delimited by braces
+// so indentation is not significant.
+func parseWireDump(in string) (roots []*wireField, err error) {
+	var stack []*wireField
+	scanner := bufio.NewScanner(strings.NewReader(in))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(in)+1)
+
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if line == "}" {
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("protowire line %d: unexpected closing brace", lineno)
+			}
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		field := &wireField{line: lineno}
+		if err = field.parse(line); err != nil {
+			return nil, fmt.Errorf("protowire line %d: %w", lineno, err)
+		}
+
+		if len(stack) == 0 {
+			roots = append(roots, field)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, field)
+		}
+
+		if field.kind == wireMessage || field.kind == wireGroup {
+			stack = append(stack, field)
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("protowire line %d: unclosed %s", stack[len(stack)-1].line, stack[len(stack)-1].kind)
+	}
+
+	if roots == nil {
+		return nil, ErrNoData
+	}
+	return roots, nil
+}
+
+// Parse a single line of the field dump, ignoring any parenthesized interpretations.
+func (f *wireField) parse(line string) (err error) {
+	numstr, rest, _ := strings.Cut(line, " ")
+	var num uint64
+	if num, err = strconv.ParseUint(numstr, 10, 32); err != nil || !protowire.Number(num).IsValid() {
+		return fmt.Errorf("invalid field number %q", numstr)
+	}
+	f.num = protowire.Number(num)
+
+	rest = strings.TrimSpace(rest)
+	for _, kind := range []string{wireMessage, wireGroup} {
+		if strings.HasPrefix(rest, kind) && strings.TrimSpace(strings.TrimPrefix(rest, kind)) == "{" {
+			f.kind = kind
+			return nil
+		}
+	}
+
+	var value string
+	f.kind, value, _ = strings.Cut(rest, ":")
+	value = strings.TrimSpace(value)
+
+	switch f.kind {
+	case wireVarint:
+		token, _, _ := strings.Cut(value, " ")
+		if f.value, err = parseVarint(token); err != nil {
+			return fmt.Errorf("invalid varint %q", token)
+		}
+	case wireFixed32, wireFixed64:
+		bits := 32
+		if f.kind == wireFixed64 {
+			bits = 64
+		}
+
+		token, _, _ := strings.Cut(value, " ")
+		if f.value, err = parseFixed(token, bits); err != nil {
+			return err
+		}
+	case wireBytes:
+		if strings.HasPrefix(value, "\"") {
+			var quoted, str string
+			if quoted, err = strconv.QuotedPrefix(value); err != nil {
+				return fmt.Errorf("invalid string value: %w", err)
+			}
+
+			if str, err = strconv.Unquote(quoted); err != nil {
+				return fmt.Errorf("invalid string value: %w", err)
+			}
+			f.bytes = []byte(str)
+		} else {
+			if f.bytes, err = hex.DecodeString(strings.Join(strings.Fields(value), "")); err != nil {
+				return fmt.Errorf("invalid bytes value: %w", err)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown wire type %q", f.kind)
+	}
+	return nil
+}
+
+// Parse a varint from hex or a decimal integer; leading zeros do not make the value octal.
+func parseVarint(token string) (_ uint64, err error) {
+	if strings.HasPrefix(token, "0x") {
+		return strconv.ParseUint(token[2:], 16, 64)
+	}
+
+	var value uint64
+	if value, err = strconv.ParseUint(token, 10, 64); err == nil {
+		return value, nil
+	}
+
+	var signed int64
+	if signed, err = strconv.ParseInt(token, 10, 64); err != nil {
+		return 0, err
+	}
+	return uint64(signed), nil
+}
+
+// Parse a fixed width value from hex, an integer, or a floating point number.
+func parseFixed(token string, bits int) (_ uint64, err error) {
+	if strings.HasPrefix(token, "0x") {
+		return strconv.ParseUint(token[2:], 16, bits)
+	}
+
+	var value uint64
+	if value, err = strconv.ParseUint(token, 10, bits); err == nil {
+		return value, nil
+	}
+
+	var signed int64
+	if signed, err = strconv.ParseInt(token, 10, bits); err == nil {
+		if bits == 32 {
+			return uint64(uint32(signed)), nil
+		}
+		return uint64(signed), nil
+	}
+
+	var float float64
+	if float, err = strconv.ParseFloat(token, bits); err == nil {
+		if bits == 32 {
+			return uint64(math.Float32bits(float32(float))), nil
+		}
+		return math.Float64bits(float), nil
+	}
+	return 0, fmt.Errorf("invalid fixed%d value %q", bits, token)
+}
@@ -0,0 +1,111 @@
+package binutil_test
+
+import (
+	"testing"
+
+	"github.com/bbengfort/binutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestProtoWire(t *testing.T) {
+	// Build a message with every wire type, including nested messages and groups
+	var nested []byte
+	nested = protowire.AppendTag(nested, 1, protowire.Fixed32Type)
+	nested = protowire.AppendFixed32(nested, 0x3f800000)
+	nested = protowire.AppendTag(nested, 2, protowire.BytesType)
+	nested = protowire.AppendBytes(nested, []byte{0xde, 0xad, 0xbe, 0xef})
+
+	var msg []byte
+	msg = protowire.AppendTag(msg, 1, protowire.VarintType)
+	msg = protowire.AppendVarint(msg, 150)
+	msg = protowire.AppendTag(msg, 2, protowire.BytesType)
+	msg = protowire.AppendBytes(msg, []byte("hello world"))
+	msg = protowire.AppendTag(msg, 3, protowire.BytesType)
+	msg = protowire.AppendBytes(msg, nested)
+	msg = protowire.AppendTag(msg, 4, protowire.VarintType)
+	msg = protowire.AppendVarint(msg, protowire.EncodeZigZag(-2))
+	msg = protowire.AppendTag(msg, 5, protowire.Fixed64Type)
+	msg = protowire.AppendFixed64(msg, 0x400921fb54442d18)
+	msg = protowire.AppendTag(msg, 6, protowire.StartGroupType)
+	msg = protowire.AppendTag(msg, 7, protowire.VarintType)
+	msg = protowire.AppendVarint(msg, 1)
+	msg = protowire.AppendTag(msg, 6, protowire.EndGroupType)
+	msg = protowire.AppendTag(msg, 8, protowire.VarintType)
+	msg = protowire.AppendVarint(msg, uint64(1<<64-1))
+	msg = protowire.AppendTag(msg, 9, protowire.BytesType)
+	msg = protowire.AppendBytes(msg, []byte{})
+
+	p := &binutil.ProtoWire{}
+	eb, err := p.DecodeBinary(msg)
+	require.NoError(t, err, "could not decode wire format")
+
+	dump, err := eb.EncodeString()
+	require.NoError(t, err, "could not dump wire format")
+
+	expected := `1 varint: 150 (int64: 150, zigzag: 75)
+2 bytes: "hello world"
+3 message {
+  1 fixed32: 0x3f800000 (int: 1065353216, float: 1)
+  2 bytes: deadbeef
+}
+4 varint: 3 (int64: 3, zigzag: -2)
+5 fixed64: 0x400921fb54442d18 (int: 4614256656552045848, double: 3.141592653589793)
+6 group {
+  7 varint: 1 (int64: 1, zigzag: -1)
+}
+8 varint: 18446744073709551615 (int64: -1, zigzag: -9223372036854775808)
+9 bytes: ""
+`
+	require.Equal(t, expected, dump)
+
+	es, err := p.DecodeString(dump)
+	require.NoError(t, err, "could not parse dump")
+
+	data, err := es.EncodeBinary()
+	require.NoError(t, err, "could not encode wire format")
+	require.Equal(t, msg, data, "expected dump to round trip to the original wire format")
+}
+
+func TestProtoWireDecodeString(t *testing.T) {
+	pipe, err := binutil.New("protowire", "hex")
+	require.NoError(t, err)
+
+	dump := `
+1 varint: -1
+2 fixed32: 1.5
+3 fixed32: -2
+4 fixed64: 42
+5 bytes: "a \"quoted\" (string)" (comment)
+6 message {
+}
+`
+	out, err := pipe.Str2Str(dump)
+	require.NoError(t, err, "could not build wire format from dump")
+	require.Equal(t, "08ffffffffffffffffff01150000c03f1dfeffffff212a000000000000002a1361202271756f746564222028737472696e67293200", out)
+
+	// Varints are decimal even with leading zeros, or hex with a 0x prefix
+	out, err = pipe.Str2Str("1 varint: 010\n2 varint: 0x10")
+	require.NoError(t, err)
+	require.Equal(t, "080a1010", out)
+
+	for _, invalid := range []string{
+		"0 varint: 1",
+		"one varint: 1",
+		"1 varint: one",
+		"1 varint: 0b101",
+		"1 varint: 1_000",
+		"1 fixed32: 0x1ffffffff",
+		"1 bytes: zz",
+		"1 float: 1.0",
+		"1 message {",
+		"}",
+		"",
+	} {
+		_, err := (&binutil.ProtoWire{}).DecodeString(invalid)
+		require.Error(t, err, "expected dump %q to be invalid", invalid)
+	}
+
+	_, err = (&binutil.ProtoWire{}).DecodeBinary([]byte{0x0a, 0x05, 0x01})
+	require.Error(t, err, "expected truncated wire format to be invalid")
+}
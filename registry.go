@@ -0,0 +1,251 @@
+package binutil
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultRegistry contains the decoders registered by this package and is used by the
+// package level functions (RegisterDecoder, NewDecoder, DecoderNames) and by pipelines
+// that are not created with the WithRegistry option.
+var DefaultRegistry = NewRegistry()
+
+// DecoderConstructors are functions that create a new Decoder ready for use.
+type DecoderConstructor func() Decoder
+
+// Registry maps decoder names and aliases to the constructors that create them. Names
+// are case insensitive. Libraries can use their own registry to isolate their decoders
+// from the DefaultRegistry (or to override names without collisions) and tests can use
+// a registry with only the decoders they require.
+type Registry struct {
+	mu         sync.RWMutex
+	decoders   map[string]decoder
+	infos      map[string]Info
+	converters map[converterKey]Converter
+}
+
+type decoder struct {
+	constructor DecoderConstructor
+	options     OptionsConstructor
+	name        string
+	alias       bool
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{decoders: make(map[string]decoder)}
+}
+
+// Register a decoder constructor so that the decoder can be referenced by the name
+// supplied or by any of its aliases. Registering a name that already exists replaces
+// the previous decoder.
+func (r *Registry) Register(name string, constructor DecoderConstructor, aliases ...string) {
+	r.register(decoder{constructor: constructor}, name, aliases)
+}
+
+// RegisterOptions registers a decoder constructor that accepts options from the step
+// specification, e.g. "pem:type=CERTIFICATE".
+func (r *Registry) RegisterOptions(name string, constructor OptionsConstructor, aliases ...string) {
+	r.register(decoder{options: constructor}, name, aliases)
+}
+
+func (r *Registry) register(dec decoder, name string, aliases []string) {
+	// All lookups are case insensitive
+	dec.name = normalizeName(name)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.decoders == nil {
+		r.decoders = make(map[string]decoder)
+	}
+
+	r.decoders[dec.name] = dec
+	for _, alias := range aliases {
+		dec.alias = true
+		r.decoders[normalizeName(alias)] = dec
+	}
+}
+
+// New creates a decoder from a step specification: a registered name or alias that may
+// be followed by options separated by a colon as described by ParseStep. If the decoder
+// was described, options that are not listed in its Info are rejected.
+func (r *Registry) New(spec string) (Decoder, error) {
+	name, opts, err := ParseStep(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	decoder, ok := r.decoders[name]
+	info, described := r.infos[decoder.name]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("no registered decoder with the name %q", name)
+	}
+
+	if decoder.options != nil {
+		// Options that are not described are rejected so that typos are not ignored;
+		// decoders that were not described accept any options.
+		if described {
+			if err = checkOptions(name, opts, info.Options); err != nil {
+				return nil, err
+			}
+		}
+		return decoder.options(opts)
+	}
+
+	if len(opts) > 0 {
+		return nil, fmt.Errorf("decoder %q does not accept options", name)
+	}
+	return decoder.constructor(), nil
+}
+
+func checkOptions(name string, opts Options, accepted []string) error {
+	keys := make([]string, 0, len(opts))
+	for key := range opts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+keys:
+	for _, key := range keys {
+		for _, option := range accepted {
+			if key == option {
+				continue keys
+			}
+		}
+		return fmt.Errorf("decoder %q does not accept the option %q", name, key)
+	}
+	return nil
+}
+
+// Names returns the sorted names of the registered decoders, excluding aliases.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]string, 0, len(r.decoders))
+	for name, decoder := range r.decoders {
+		if !decoder.alias {
+			out = append(out, name)
+		}
+	}
+
+	sort.Strings(out)
+	return out
+}
+
+// Aliases returns the sorted aliases of the decoder registered with the name (or with
+// an alias of the decoder). Nil is returned if there is no such decoder.
+func (r *Registry) Aliases(name string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	decoder, ok := r.decoders[normalizeName(name)]
+	if !ok {
+		return nil
+	}
+
+	out := make([]string, 0)
+	for alias, dec := range r.decoders {
+		if dec.alias && dec.name == decoder.name {
+			out = append(out, alias)
+		}
+	}
+
+	sort.Strings(out)
+	return out
+}
+
+// Clone returns a copy of the registry that can be modified independently.
+func (r *Registry) Clone() *Registry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	clone := NewRegistry()
+	for name, decoder := range r.decoders {
+		clone.decoders[name] = decoder
+	}
+
+	if r.infos != nil {
+		clone.infos = make(map[string]Info, len(r.infos))
+		for name, info := range r.infos {
+			clone.infos[name] = info
+		}
+	}
+
+	if r.converters != nil {
+		clone.converters = make(map[converterKey]Converter, len(r.converters))
+		for key, converter := range r.converters {
+			clone.converters[key] = converter
+		}
+	}
+	return clone
+}
+
+// Register a decoder constructor with the DefaultRegistry so that the decoder can be
+// referenced by the name suplied and users can instantiate it directly from the type
+// name. Note that names are case insensitive so MyDecoder is the same as mydecoder.
+func RegisterDecoder(name string, constructor DecoderConstructor, aliases ...string) {
+	DefaultRegistry.Register(name, constructor, aliases...)
+}
+
+// Register a decoder constructor that accepts options from the step specification with
+// the DefaultRegistry, e.g. "pem:type=CERTIFICATE". Decoders registered without options
+// return an error if the user attempts to specify options for them.
+func RegisterOptionsDecoder(name string, constructor OptionsConstructor, aliases ...string) {
+	DefaultRegistry.RegisterOptions(name, constructor, aliases...)
+}
+
+// Register a converter with the DefaultRegistry that Pipeline.Compile uses to fuse
+// adjacent from and to steps into a single step.
+func RegisterConverter(from, to string, converter Converter) {
+	DefaultRegistry.RegisterConverter(from, to, converter)
+}
+
+// Create a decoder from the DefaultRegistry by name rather than by directly
+// instantiating one. The name may be followed by options separated by a colon as
+// described by ParseStep.
+func NewDecoder(spec string) (Decoder, error) {
+	return DefaultRegistry.New(spec)
+}
+
+// DecoderNames returns the names of the decoders in the DefaultRegistry.
+func DecoderNames() []string {
+	return DefaultRegistry.Names()
+}
+
+func normalizeName(name string) string {
+	return strings.TrimSpace(strings.ToLower(name))
+}
+
+// PipelineOption configures how New and NewMultiOutputs create pipelines.
+type PipelineOption func(*pipelineConfig)
+
+type pipelineConfig struct {
+	registry *Registry
+	limits   Limits
+}
+
+// WithRegistry looks up decoder specifications in the registry rather than in the
+// DefaultRegistry.
+func WithRegistry(registry *Registry) PipelineOption {
+	return func(conf *pipelineConfig) {
+		if registry != nil {
+			conf.registry = registry
+		}
+	}
+}
+
+func newPipelineConfig(steps []any) *pipelineConfig {
+	conf := &pipelineConfig{registry: DefaultRegistry}
+	for _, step := range steps {
+		if opt, ok := step.(PipelineOption); ok {
+			opt(conf)
+		}
+	}
+	return conf
+}
@@ -0,0 +1,102 @@
+package binutil_test
+
+import (
+	"testing"
+
+	"github.com/bbengfort/binutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryOptions(t *testing.T) {
+	// A typo in the options of the default decoders is an error rather than ignored
+	_, err := binutil.NewDecoder("json:pretty,sort")
+	require.EqualError(t, err, `decoder "json" does not accept the option "sort"`)
+
+	t.Setenv("BINUTIL_TEST_KEY", "hex:000102030405060708090a0b0c0d0e0f")
+	_, err = binutil.NewDecoder("aesgcm:key=env:BINUTIL_TEST_KEY,add=env:BINUTIL_TEST_AAD")
+	require.EqualError(t, err, `decoder "aesgcm" does not accept the option "add"`)
+
+	_, err = binutil.NewDecoder("aes-gcm:key=env:BINUTIL_TEST_KEY,aad=hello")
+	require.NoError(t, err)
+}
+
+func TestRegistry(t *testing.T) {
+	registry := binutil.NewRegistry()
+	registry.Register("Hex", func() binutil.Decoder { return &binutil.Hex{} }, "HEXADECIMAL", " h ")
+	registry.RegisterOptions("pem", func(opts binutil.Options) (binutil.Decoder, error) { return binutil.NewPEMFromOptions(opts) })
+
+	require.Equal(t, []string{"hex", "pem"}, registry.Names())
+	require.Equal(t, []string{"h", "hexadecimal"}, registry.Aliases("hex"))
+	require.Equal(t, []string{"h", "hexadecimal"}, registry.Aliases("H"), "expected aliases to be looked up by alias")
+	require.Empty(t, registry.Aliases("pem"))
+	require.Nil(t, registry.Aliases("b64"))
+
+	dec, err := registry.New("hexadecimal")
+	require.NoError(t, err)
+	require.IsType(t, &binutil.Hex{}, dec)
+
+	_, err = registry.New("pem:type=CERTIFICATE")
+	require.NoError(t, err)
+
+	// Options are not checked if the decoder is not described
+	_, err = registry.New("pem:type=CERTIFICATE,typo")
+	require.NoError(t, err)
+
+	registry.Describe("pem", binutil.Info{Options: []string{"type", "index"}})
+	_, err = registry.New("pem:type=CERTIFICATE,index=0")
+	require.NoError(t, err)
+
+	_, err = registry.New("pem:type=CERTIFICATE,typo,indx=1")
+	require.EqualError(t, err, `decoder "pem" does not accept the option "indx"`)
+
+	_, err = registry.New("hex:upper")
+	require.EqualError(t, err, `decoder "hex" does not accept options`)
+
+	_, err = registry.New("b64")
+	require.EqualError(t, err, `no registered decoder with the name "b64"`)
+
+	// The zero value registry can be used
+	zero := &binutil.Registry{}
+	require.Empty(t, zero.Names())
+	zero.Register("hex", func() binutil.Decoder { return &binutil.Hex{} })
+	require.Equal(t, []string{"hex"}, zero.Names())
+}
+
+func TestRegistryIsolation(t *testing.T) {
+	// A cloned registry can override names without affecting the default registry
+	registry := binutil.DefaultRegistry.Clone()
+	registry.Register("b64", func() binutil.Decoder { return &binutil.Hex{} })
+
+	pipe, err := binutil.New("b64", binutil.WithRegistry(registry))
+	require.NoError(t, err)
+
+	out, err := pipe.Bin2Str([]byte{0xde, 0xad})
+	require.NoError(t, err)
+	require.Equal(t, "dead", out)
+
+	pipe, err = binutil.New("b64")
+	require.NoError(t, err)
+
+	out, err = pipe.Bin2Str([]byte{0xde, 0xad})
+	require.NoError(t, err)
+	require.Equal(t, "3q0=", out)
+
+	// A trimmed registry only contains the registered decoders
+	trimmed := binutil.NewRegistry()
+	trimmed.Register("hex", func() binutil.Decoder { return &binutil.Hex{} })
+
+	_, err = binutil.New("hex", "b64", binutil.WithRegistry(trimmed))
+	require.Error(t, err, "expected b64 to be missing from the trimmed registry")
+
+	hex := binutil.Output{Label: "hex", Steps: []any{"hex"}}
+	multi, err := binutil.NewMultiOutputs([]binutil.Output{hex}, binutil.WithRegistry(trimmed))
+	require.NoError(t, err)
+	require.Equal(t, "dead", multi.MustBin2Str("hex", []byte{0xde, 0xad}))
+
+	b64 := binutil.Output{Label: "b64", Steps: []any{"b64"}}
+	_, err = binutil.NewMultiOutputs([]binutil.Output{hex, b64}, binutil.WithRegistry(trimmed))
+	require.Error(t, err, "expected b64 to be missing from the trimmed registry")
+
+	_, err = binutil.NewMultiOutputs([]binutil.Output{{Label: "int", Steps: []any{42}}})
+	require.ErrorIs(t, err, binutil.ErrUnknownStepType)
+}
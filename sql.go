@@ -0,0 +1,255 @@
+package binutil
+
+import (
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// SQL dialects that binary literals can be written for.
+const (
+	PostgresDialect SQLDialect = iota
+	MySQLDialect
+	SQLiteDialect
+	MSSQLDialect
+	OracleDialect
+)
+
+// SQL literal forms; not every form is available in every dialect.
+const (
+	SQLHexLiteral   = "hex"
+	SQLByteaLiteral = "bytea"
+	SQLUnhexLiteral = "unhex"
+	SQLUUIDLiteral  = "uuid"
+)
+
+func init() {
+	RegisterOptionsDecoder(SQLPostgresDecoder, sqlConstructor(PostgresDialect), "sql-postgresql", "sql-pg")
+	RegisterOptionsDecoder(SQLMySQLDecoder, sqlConstructor(MySQLDialect), "sql-mariadb")
+	RegisterOptionsDecoder(SQLSQLiteDecoder, sqlConstructor(SQLiteDialect), "sql-sqlite3")
+	RegisterOptionsDecoder(SQLMSSQLDecoder, sqlConstructor(MSSQLDialect), "sql-sqlserver", "sql-tsql")
+	RegisterOptionsDecoder(SQLOracleDecoder, sqlConstructor(OracleDialect))
+
+	DescribeDecoder(SQLPostgresDecoder, Info{Description: "PostgreSQL bytea and uuid literals", Category: RadixCategory, Capabilities: Bidirectional, Options: []string{"as"}})
+	DescribeDecoder(SQLMySQLDecoder, Info{Description: "MySQL hex, UNHEX, and UUID_TO_BIN literals", Category: RadixCategory, Capabilities: Bidirectional, Options: []string{"as", "swap"}})
+	DescribeDecoder(SQLSQLiteDecoder, Info{Description: "SQLite blob literals", Category: RadixCategory, Capabilities: Bidirectional, Options: []string{"as"}})
+	DescribeDecoder(SQLMSSQLDecoder, Info{Description: "SQL Server binary literals", Category: RadixCategory, Capabilities: Bidirectional, Options: []string{"as"}})
+	DescribeDecoder(SQLOracleDecoder, Info{Description: "Oracle HEXTORAW literals", Category: RadixCategory, Capabilities: Bidirectional, Options: []string{"as"}})
+}
+
+const (
+	SQLPostgresDecoder = "sql-postgres"
+	SQLMySQLDecoder    = "sql-mysql"
+	SQLSQLiteDecoder   = "sql-sqlite"
+	SQLMSSQLDecoder    = "sql-mssql"
+	SQLOracleDecoder   = "sql-oracle"
+)
+
+func sqlConstructor(dialect SQLDialect) OptionsConstructor {
+	return func(opts Options) (Decoder, error) { return NewSQLFromOptions(dialect, opts) }
+}
+
+// The literal forms available in each dialect; the first form is the default.
+var sqlLiterals = map[SQLDialect][]string{
+	PostgresDialect: {SQLByteaLiteral, SQLUUIDLiteral},
+	MySQLDialect:    {SQLHexLiteral, SQLUnhexLiteral, SQLUUIDLiteral},
+	SQLiteDialect:   {SQLHexLiteral},
+	MSSQLDialect:    {SQLHexLiteral},
+	OracleDialect:   {SQLHexLiteral},
+}
+
+func NewSQL(dialect SQLDialect) *SQL {
+	return &SQL{Dialect: dialect, As: sqlLiterals[dialect][0]}
+}
+
+// NewSQLFromOptions creates a SQL literal step from the step specification options. The
+// as option selects the literal form written by the step:
+//
+//	sql-postgres   bytea: '\x0102'::bytea, uuid: '...'::uuid
+//	sql-mysql      hex: X'0102', unhex: UNHEX('0102'), uuid: UUID_TO_BIN('...')
+//	sql-sqlite     hex: X'0102'
+//	sql-mssql      hex: 0x0102
+//	sql-oracle     hex: HEXTORAW('0102')
+//
+// The swap flag writes UUID_TO_BIN('...', 1) for MySQL uuid literals so that MySQL
+// stores the time fields of a version 1 UUID first; the UUID in the literal is the UUID
+// that was converted, MySQL swaps the fields when the query is run. The flag is rejected
+// by the other dialects and literal forms.
+func NewSQLFromOptions(dialect SQLDialect, opts Options) (_ *SQL, err error) {
+	s := NewSQL(dialect)
+	s.As = strings.ToLower(opts.Get("as", s.As))
+	if !s.supports(s.As) {
+		return nil, fmt.Errorf("%s does not support %q literals, use one of %s", dialect, s.As, strings.Join(sqlLiterals[dialect], ", "))
+	}
+
+	if s.Swap, err = opts.Bool("swap"); err != nil {
+		return nil, err
+	}
+
+	if s.Swap && (dialect != MySQLDialect || s.As != SQLUUIDLiteral) {
+		return nil, fmt.Errorf("the swap flag requires mysql uuid literals, not %s %q literals", dialect, s.As)
+	}
+	return s, nil
+}
+
+// SQL implements the encoder and decoder interface for binary literals in SQL queries.
+// The binary representation is the raw bytes and the string representation is a
+// literal expression in the step's dialect, so that IDs can be copied into queries
+// (e.g. ulid to sql-postgres) and values copied from query output can be converted back
+// (e.g. sql-mysql to ulid). When decoding strings every literal form supported by the
+// dialect is accepted regardless of the as option.
+type SQL struct {
+	Dialect SQLDialect
+	As      string
+	Swap    bool
+	data    []byte
+}
+
+var (
+	_ Encoder = &SQL{}
+	_ Decoder = &SQL{}
+)
+
+// DecodeBinary stores the raw bytes.
+func (s SQL) DecodeBinary(in []byte) (_ Encoder, err error) {
+	return &SQL{Dialect: s.Dialect, As: s.As, Swap: s.Swap, data: in}, nil
+}
+
+// DecodeString parses a binary or uuid literal in the dialect.
+func (s SQL) DecodeString(in string) (_ Encoder, err error) {
+	lit := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(in), ";"))
+	for _, pattern := range sqlPatterns[s.Dialect] {
+		match := pattern.re.FindStringSubmatch(lit)
+		if match == nil {
+			continue
+		}
+
+		out := &SQL{Dialect: s.Dialect, As: s.As, Swap: s.Swap}
+		if out.data, err = pattern.parse(match); err != nil {
+			return nil, fmt.Errorf("invalid %s literal: %w", s.Dialect, err)
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("could not parse %q as a %s binary literal", in, s.Dialect)
+}
+
+// EncodeBinary returns the raw bytes.
+func (s SQL) EncodeBinary() ([]byte, error) {
+	if s.data == nil {
+		return nil, ErrNoData
+	}
+	return s.data, nil
+}
+
+// EncodeString writes the bytes as a literal in the dialect.
+func (s SQL) EncodeString() (_ string, err error) {
+	if s.data == nil {
+		return "", ErrNoData
+	}
+
+	if s.As == SQLUUIDLiteral {
+		var u uuid.UUID
+		if u, err = uuid.FromBytes(s.data); err != nil {
+			return "", fmt.Errorf("uuid literals require 16 bytes, got %d", len(s.data))
+		}
+
+		if s.Dialect == MySQLDialect {
+			if s.Swap {
+				return fmt.Sprintf("UUID_TO_BIN('%s', 1)", u), nil
+			}
+			return fmt.Sprintf("UUID_TO_BIN('%s')", u), nil
+		}
+		return fmt.Sprintf("'%s'::uuid", u), nil
+	}
+
+	switch s.Dialect {
+	case PostgresDialect:
+		return fmt.Sprintf(`'\x%x'::bytea`, s.data), nil
+	case MySQLDialect:
+		if s.As == SQLUnhexLiteral {
+			return fmt.Sprintf("UNHEX('%X')", s.data), nil
+		}
+		return fmt.Sprintf("X'%X'", s.data), nil
+	case SQLiteDialect:
+		return fmt.Sprintf("X'%X'", s.data), nil
+	case MSSQLDialect:
+		return fmt.Sprintf("0x%X", s.data), nil
+	case OracleDialect:
+		return fmt.Sprintf("HEXTORAW('%X')", s.data), nil
+	default:
+		return "", fmt.Errorf("unknown sql dialect %s", s.Dialect)
+	}
+}
+
+func (s SQL) supports(as string) bool {
+	for _, literal := range sqlLiterals[s.Dialect] {
+		if literal == as {
+			return true
+		}
+	}
+	return false
+}
+
+type sqlPattern struct {
+	re    *regexp.Regexp
+	parse func(match []string) ([]byte, error)
+}
+
+func hexLiteral(match []string) ([]byte, error) {
+	return hex.DecodeString(match[1])
+}
+
+func uuidLiteral(match []string) ([]byte, error) {
+	u, err := uuid.Parse(match[1])
+	if err != nil {
+		return nil, err
+	}
+	return u[:], nil
+}
+
+var sqlPatterns = map[SQLDialect][]sqlPattern{
+	PostgresDialect: {
+		{regexp.MustCompile(`(?i)^E?'\\{1,2}x([0-9a-f]*)'(?:\s*::\s*bytea)?$`), hexLiteral},
+		{regexp.MustCompile(`(?i)^'([0-9a-f-]{32,36})'\s*::\s*uuid$`), uuidLiteral},
+		{regexp.MustCompile(`(?i)^decode\(\s*'([0-9a-f]*)'\s*,\s*'hex'\s*\)$`), hexLiteral},
+	},
+	MySQLDialect: {
+		{regexp.MustCompile(`(?i)^x'([0-9a-f]*)'$`), hexLiteral},
+		{regexp.MustCompile(`(?i)^0x([0-9a-f]+)$`), hexLiteral},
+		{regexp.MustCompile(`(?i)^unhex\(\s*'([0-9a-f]*)'\s*\)$`), hexLiteral},
+		// The swap flag of UUID_TO_BIN only changes how MySQL stores the UUID, so the
+		// bytes of the UUID in the literal are returned either way.
+		{regexp.MustCompile(`(?i)^uuid_to_bin\(\s*'([0-9a-f-]{32,36})'\s*(?:,\s*(?:0|1|false|true)\s*)?\)$`), uuidLiteral},
+	},
+	SQLiteDialect: {
+		{regexp.MustCompile(`(?i)^x'([0-9a-f]*)'$`), hexLiteral},
+	},
+	MSSQLDialect: {
+		{regexp.MustCompile(`(?i)^0x([0-9a-f]*)$`), hexLiteral},
+	},
+	OracleDialect: {
+		{regexp.MustCompile(`(?i)^hextoraw\(\s*'([0-9a-f]*)'\s*\)$`), hexLiteral},
+	},
+}
+
+type SQLDialect uint8
+
+func (d SQLDialect) String() string {
+	switch d {
+	case PostgresDialect:
+		return "postgres"
+	case MySQLDialect:
+		return "mysql"
+	case SQLiteDialect:
+		return "sqlite"
+	case MSSQLDialect:
+		return "mssql"
+	case OracleDialect:
+		return "oracle"
+	default:
+		return "unknown"
+	}
+}
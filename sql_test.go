@@ -0,0 +1,132 @@
+package binutil_test
+
+import (
+	"testing"
+
+	"github.com/bbengfort/binutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLLiterals(t *testing.T) {
+	data := []byte{0x9d, 0x83, 0x86, 0xfa, 0x18, 0xe9, 0x58, 0x50, 0x06, 0x26, 0xc6, 0x81, 0xa0, 0xba, 0xd2, 0x7a}
+	testCases := []struct {
+		spec     string
+		expected string
+	}{
+		{"sql-postgres", `'\x9d8386fa18e958500626c681a0bad27a'::bytea`},
+		{"sql-postgres:as=uuid", `'9d8386fa-18e9-5850-0626-c681a0bad27a'::uuid`},
+		{"sql-mysql", `X'9D8386FA18E958500626C681A0BAD27A'`},
+		{"sql-mysql:as=unhex", `UNHEX('9D8386FA18E958500626C681A0BAD27A')`},
+		{"sql-mysql:as=uuid", `UUID_TO_BIN('9d8386fa-18e9-5850-0626-c681a0bad27a')`},
+		{"sql-mysql:as=uuid,swap", `UUID_TO_BIN('9d8386fa-18e9-5850-0626-c681a0bad27a', 1)`},
+		{"sql-sqlite", `X'9D8386FA18E958500626C681A0BAD27A'`},
+		{"sql-mssql", `0x9D8386FA18E958500626C681A0BAD27A`},
+		{"sql-oracle", `HEXTORAW('9D8386FA18E958500626C681A0BAD27A')`},
+	}
+
+	for _, tc := range testCases {
+		pipe, err := binutil.New(tc.spec)
+		require.NoError(t, err, "could not create pipeline for %s", tc.spec)
+
+		out, err := pipe.Bin2Str(data)
+		require.NoError(t, err, "could not encode literal for %s", tc.spec)
+		require.Equal(t, tc.expected, out, "unexpected literal for %s", tc.spec)
+
+		parsed, err := pipe.Str2Bin(out)
+		require.NoError(t, err, "could not parse literal for %s", tc.spec)
+		require.Equal(t, data, parsed, "expected literal to round trip for %s", tc.spec)
+	}
+}
+
+func TestSQLParse(t *testing.T) {
+	testCases := []struct {
+		spec string
+		in   string
+	}{
+		{"sql-postgres", `'\x0102ab'`},
+		{"sql-postgres", `E'\\x0102AB'::BYTEA;`},
+		{"sql-postgres", `decode('0102ab', 'hex')`},
+		{"sql-mysql", `x'0102ab'`},
+		{"sql-mysql", `0x0102AB`},
+		{"sql-mysql", `unhex( '0102ab' )`},
+		{"sql-sqlite", ` X'0102AB' `},
+		{"sql-mssql", `0x0102ab`},
+		{"sql-oracle", `hextoraw('0102AB')`},
+	}
+
+	for _, tc := range testCases {
+		pipe, err := binutil.New(tc.spec)
+		require.NoError(t, err, "could not create pipeline for %s", tc.spec)
+
+		data, err := pipe.Str2Bin(tc.in)
+		require.NoError(t, err, "could not parse %q with %s", tc.in, tc.spec)
+		require.Equal(t, []byte{0x01, 0x02, 0xab}, data, "unexpected bytes for %q with %s", tc.in, tc.spec)
+	}
+
+	// The uuid literal is parsed regardless of the as option
+	pipe, err := binutil.New("sql-postgres", "ulid")
+	require.NoError(t, err)
+
+	out, err := pipe.Str2Str(`'0188f83a-752a-229f-5e14-b8052b4be0de'::uuid`)
+	require.NoError(t, err)
+	require.Equal(t, "01H3W3MX9A4AFNW55R0MNMQR6Y", out)
+}
+
+func TestSQLSwap(t *testing.T) {
+	// The literal contains the UUID that was converted, not the bytes MySQL stores
+	pipe, err := binutil.New("uuid", "sql-mysql:as=uuid,swap")
+	require.NoError(t, err)
+
+	out, err := pipe.Str2Str("9d8386fa-18e9-5850-0626-c681a0bad27a")
+	require.NoError(t, err)
+	require.Equal(t, `UUID_TO_BIN('9d8386fa-18e9-5850-0626-c681a0bad27a', 1)`, out)
+
+	inv, err := pipe.Inverse()
+	require.NoError(t, err)
+
+	for _, literal := range []string{out, `UUID_TO_BIN('9d8386fa-18e9-5850-0626-c681a0bad27a', true)`, `uuid_to_bin('9d8386fa18e958500626c681a0bad27a')`} {
+		out, err = inv.Str2Str(literal)
+		require.NoError(t, err)
+		require.Equal(t, "9d8386fa-18e9-5850-0626-c681a0bad27a", out, "could not parse %q", literal)
+	}
+
+	_, err = binutil.New("sql-mysql:swap")
+	require.EqualError(t, err, `the swap flag requires mysql uuid literals, not mysql "hex" literals`)
+
+	_, err = binutil.New("sql-mysql:as=unhex,swap")
+	require.EqualError(t, err, `the swap flag requires mysql uuid literals, not mysql "unhex" literals`)
+
+	_, err = binutil.NewSQLFromOptions(binutil.PostgresDialect, binutil.Options{"as": "uuid", "swap": "true"})
+	require.Error(t, err, "expected swap to be rejected by postgres")
+
+	_, err = binutil.New("sql-postgres:as=uuid,swap")
+	require.Error(t, err, "expected swap to be rejected by postgres")
+
+	_, err = binutil.New("sql-mysql:as=uuid,swap=false")
+	require.NoError(t, err)
+}
+
+func TestSQLErrors(t *testing.T) {
+	_, err := binutil.New("sql-sqlite:as=uuid")
+	require.Error(t, err, "expected unsupported literal form to be rejected")
+
+	pipe, err := binutil.New("sql-postgres:as=uuid")
+	require.NoError(t, err)
+
+	_, err = pipe.Bin2Str([]byte{0x01, 0x02})
+	require.EqualError(t, err, "could not encode string in step 0: uuid literals require 16 bytes, got 2")
+
+	for _, tc := range []struct{ spec, in string }{
+		{"sql-mssql", `X'0102'`},
+		{"sql-sqlite", `0x0102`},
+		{"sql-oracle", `'0102'`},
+		{"sql-mysql", `X'012'`},
+		{"sql-postgres", `'not-a-uuid'::uuid`},
+	} {
+		pipe, err := binutil.New(tc.spec)
+		require.NoError(t, err)
+
+		_, err = pipe.Str2Bin(tc.in)
+		require.Error(t, err, "expected %q to be rejected by %s", tc.in, tc.spec)
+	}
+}
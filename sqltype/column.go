@@ -0,0 +1,94 @@
+// Package sqltype provides database/sql adapters for binutil types so that values such
+// as ULIDs and UUIDs can be stored in binary (BLOB or BYTEA) columns without writing a
+// scanner for each type.
+package sqltype
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	"github.com/bbengfort/binutil"
+)
+
+// Codec is a binutil type that can be both decoded and encoded, e.g. binutil.ULID or
+// binutil.UUID. The zero value of the type must be ready to decode.
+type Codec interface {
+	binutil.Encoder
+	binutil.Decoder
+}
+
+// Column is a nullable column of a binutil type that implements sql.Scanner and
+// driver.Valuer. Values are stored in their binary representation (EncodeBinary) and
+// can be scanned from either the binary or the string representation, e.g. a ULID can
+// be scanned from a 16 byte BLOB or from a 26 character TEXT column. Valid is false if
+// the column is NULL.
+type Column[T Codec] struct {
+	Data  T
+	Valid bool
+}
+
+var (
+	_ sql.Scanner   = &Column[binutil.ULID]{}
+	_ driver.Valuer = Column[binutil.ULID]{}
+)
+
+// New returns a valid column with the specified data.
+func New[T Codec](data T) Column[T] {
+	return Column[T]{Data: data, Valid: true}
+}
+
+// Scan implements sql.Scanner. Byte slices are decoded as the binary representation
+// and, if that fails, as the string representation since some drivers return text
+// columns as bytes; strings are decoded as the string representation first.
+func (c *Column[T]) Scan(src any) (err error) {
+	var zero T
+	if src == nil {
+		c.Data, c.Valid = zero, false
+		return nil
+	}
+
+	var enc binutil.Encoder
+	switch v := src.(type) {
+	case []byte:
+		// The driver may reuse the buffer after Scan returns
+		data := append([]byte(nil), v...)
+		if enc, err = zero.DecodeBinary(data); err != nil {
+			var serr error
+			if enc, serr = zero.DecodeString(string(data)); serr != nil {
+				return fmt.Errorf("could not scan %T: %w", zero, err)
+			}
+		}
+	case string:
+		if enc, err = zero.DecodeString(v); err != nil {
+			var berr error
+			if enc, berr = zero.DecodeBinary([]byte(v)); berr != nil {
+				return fmt.Errorf("could not scan %T: %w", zero, err)
+			}
+		}
+	default:
+		return fmt.Errorf("cannot scan %T into %T", src, zero)
+	}
+
+	switch t := any(enc).(type) {
+	case T:
+		c.Data = t
+	case *T:
+		c.Data = *t
+	default:
+		return errors.New("decoder returned an unexpected type")
+	}
+
+	c.Valid = true
+	return nil
+}
+
+// Value implements driver.Valuer, returning the binary representation or nil if the
+// column is not valid.
+func (c Column[T]) Value() (driver.Value, error) {
+	if !c.Valid {
+		return nil, nil
+	}
+	return c.Data.EncodeBinary()
+}
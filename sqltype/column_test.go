@@ -0,0 +1,70 @@
+package sqltype_test
+
+import (
+	"testing"
+
+	"github.com/bbengfort/binutil"
+	"github.com/bbengfort/binutil/sqltype"
+	"github.com/google/uuid"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestULIDColumn(t *testing.T) {
+	id := ulid.MustParse("01H3W3MX9A4AFNW55R0MNMQR6Y")
+	col := sqltype.New(binutil.ULID{ULID: id})
+
+	val, err := col.Value()
+	require.NoError(t, err, "could not get column value")
+	require.Equal(t, id.Bytes(), val, "expected the binary representation to be stored")
+
+	// Scan the binary representation; the driver buffer must not be retained
+	buf := append([]byte(nil), id.Bytes()...)
+	scanned := &sqltype.Column[binutil.ULID]{}
+	require.NoError(t, scanned.Scan(buf), "could not scan binary ulid")
+	for i := range buf {
+		buf[i] = 0
+	}
+	require.True(t, scanned.Valid)
+	require.Equal(t, id, scanned.Data.ULID)
+
+	// Scan the string representation as a string or as bytes
+	scanned = &sqltype.Column[binutil.ULID]{}
+	require.NoError(t, scanned.Scan(id.String()), "could not scan string ulid")
+	require.True(t, scanned.Valid)
+	require.Equal(t, id, scanned.Data.ULID)
+
+	scanned = &sqltype.Column[binutil.ULID]{}
+	require.NoError(t, scanned.Scan([]byte(id.String())), "could not scan string ulid from bytes")
+	require.Equal(t, id, scanned.Data.ULID)
+
+	// NULL values reset the column
+	require.NoError(t, scanned.Scan(nil), "could not scan null")
+	require.False(t, scanned.Valid)
+	require.Equal(t, binutil.ULID{}, scanned.Data)
+
+	val, err = scanned.Value()
+	require.NoError(t, err)
+	require.Nil(t, val, "expected invalid column to be null")
+}
+
+func TestUUIDColumn(t *testing.T) {
+	id := uuid.MustParse("9d8386fa-18e9-5850-0626-c681a0bad27a")
+
+	col := &sqltype.Column[binutil.UUID]{}
+	require.NoError(t, col.Scan(id.String()), "could not scan string uuid")
+	require.True(t, col.Valid)
+	require.Equal(t, id, col.Data.UUID)
+
+	val, err := col.Value()
+	require.NoError(t, err)
+	require.Equal(t, id[:], val)
+}
+
+func TestColumnScanErrors(t *testing.T) {
+	col := &sqltype.Column[binutil.ULID]{}
+	require.Error(t, col.Scan([]byte{0x01, 0x02}), "expected invalid bytes to be rejected")
+	require.Error(t, col.Scan("not a ulid"), "expected invalid string to be rejected")
+	require.EqualError(t, col.Scan(int64(42)), "cannot scan int64 into binutil.ULID")
+	require.False(t, col.Valid)
+}
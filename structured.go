@@ -0,0 +1,774 @@
+package binutil
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Structured text formats that can be converted between each other.
+const (
+	JSONFormat StructuredFormat = iota
+	YAMLFormat
+	TOMLFormat
+)
+
+func init() {
+	RegisterOptionsDecoder(JSONDecoder, func(opts Options) (Decoder, error) { return NewStructuredFromOptions(JSONFormat, opts) })
+	RegisterOptionsDecoder(YAMLDecoder, func(opts Options) (Decoder, error) { return NewStructuredFromOptions(YAMLFormat, opts) }, "yml")
+	RegisterOptionsDecoder(TOMLDecoder, func(opts Options) (Decoder, error) { return NewStructuredFromOptions(TOMLFormat, opts) })
+
+	structuredOptions := []string{"compact", "pretty", "sorted", "canonical", "indent"}
+	DescribeDecoder(JSONDecoder, Info{Description: "JSON documents", Category: StructuredCategory, Capabilities: Bidirectional | Lossy, Options: structuredOptions})
+	DescribeDecoder(YAMLDecoder, Info{Description: "YAML documents with a JSON binary representation", Category: StructuredCategory, Capabilities: Bidirectional | Lossy, Options: structuredOptions})
+	DescribeDecoder(TOMLDecoder, Info{Description: "TOML documents with a JSON binary representation", Category: StructuredCategory, Capabilities: Bidirectional | Lossy, Options: structuredOptions})
+}
+
+const (
+	JSONDecoder = "json"
+	YAMLDecoder = "yaml"
+	TOMLDecoder = "toml"
+)
+
+// DefaultIndent is the number of spaces used to indent pretty printed documents.
+const DefaultIndent = 2
+
+func NewStructured(format StructuredFormat) *Structured {
+	return &Structured{Format: format, Indent: DefaultIndent}
+}
+
+// NewStructuredFromOptions creates a structured text step from the step specification
+// options. The compact flag renders the string representation on a single line (or in
+// flow style for YAML), the pretty flag indents the JSON binary representation, the
+// indent option sets the number of spaces to indent by, the sorted flag sorts object
+// keys, and the canonical flag produces RFC 8785 canonical JSON.
+func NewStructuredFromOptions(format StructuredFormat, opts Options) (_ *Structured, err error) {
+	s := NewStructured(format)
+	if s.Compact, err = opts.Bool("compact"); err != nil {
+		return nil, err
+	}
+
+	if s.Pretty, err = opts.Bool("pretty"); err != nil {
+		return nil, err
+	}
+
+	if s.Sorted, err = opts.Bool("sorted"); err != nil {
+		return nil, err
+	}
+
+	if s.Canonical, err = opts.Bool("canonical"); err != nil {
+		return nil, err
+	}
+
+	if s.Indent, err = opts.Int("indent", DefaultIndent); err != nil {
+		return nil, err
+	}
+
+	if s.Indent < 0 {
+		return nil, errors.New("indent must not be negative")
+	}
+	return s, nil
+}
+
+// Structured implements the encoder and decoder interface for JSON, YAML, and TOML
+// documents. The string representation is the document in the step's format and the
+// binary representation is always JSON, so steps can be chained to convert documents
+// between formats, e.g. yaml to json or json to toml; the input is parsed and
+// re-encoded rather than passed through, so invalid documents are rejected. When
+// decoding binary data YAML and TOML steps accept either JSON or their own syntax.
+//
+// The key order of JSON and YAML documents is preserved unless the sorted flag is
+// set; TOML tables are always written with sorted keys. The string representation is
+// indented unless the compact flag is set and the binary representation is compact
+// unless the pretty flag is set. The canonical flag produces RFC 8785 (JCS) JSON: keys
+// sorted by UTF-16 code units, no whitespace, and ECMAScript number formatting so that
+// the output can be hashed or signed reproducibly.
+type Structured struct {
+	Format    StructuredFormat
+	Compact   bool
+	Pretty    bool
+	Sorted    bool
+	Canonical bool
+	Indent    int
+	value     any
+}
+
+var (
+	_ Encoder = &Structured{}
+	_ Decoder = &Structured{}
+)
+
+// DecodeBinary parses JSON or, for YAML and TOML steps, a document in the step format.
+func (s Structured) DecodeBinary(in []byte) (_ Encoder, err error) {
+	if s.Format == JSONFormat || json.Valid(in) {
+		out := s
+		if out.value, err = parseOrderedJSON(in); err != nil {
+			return nil, err
+		}
+		return &out, nil
+	}
+	return s.DecodeString(string(in))
+}
+
+// DecodeString parses a document in the step format.
+func (s Structured) DecodeString(in string) (_ Encoder, err error) {
+	out := s
+	switch s.Format {
+	case JSONFormat:
+		out.value, err = parseOrderedJSON([]byte(in))
+	case YAMLFormat:
+		out.value, err = parseOrderedYAML([]byte(in))
+	case TOMLFormat:
+		out.value, err = parseOrderedTOML(in)
+	default:
+		return nil, fmt.Errorf("unknown structured format %s", s.Format)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// EncodeBinary renders the document as JSON, compact unless the pretty flag is set.
+func (s Structured) EncodeBinary() ([]byte, error) {
+	return s.marshalJSON(s.Pretty)
+}
+
+// EncodeString renders the document in the step format.
+func (s Structured) EncodeString() (_ string, err error) {
+	var data []byte
+	switch s.Format {
+	case JSONFormat:
+		data, err = s.marshalJSON(!s.Compact)
+	case YAMLFormat:
+		data, err = s.marshalYAML()
+	case TOMLFormat:
+		data, err = s.marshalTOML()
+	default:
+		return "", fmt.Errorf("unknown structured format %s", s.Format)
+	}
+
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Value returns the decoded document; objects are returned as map[string]any.
+func (s Structured) Value() any {
+	return plainValue(s.value)
+}
+
+func (s Structured) marshalJSON(pretty bool) (_ []byte, err error) {
+	buf := &bytes.Buffer{}
+	w := jsonWriter{sorted: s.Sorted, canonical: s.Canonical}
+	if err = w.write(buf, s.value); err != nil {
+		return nil, err
+	}
+
+	if !pretty || s.Canonical {
+		return buf.Bytes(), nil
+	}
+
+	out := &bytes.Buffer{}
+	if err = json.Indent(out, buf.Bytes(), "", strings.Repeat(" ", s.Indent)); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func (s Structured) marshalYAML() (_ []byte, err error) {
+	var node *yaml.Node
+	if node, err = s.yamlNode(s.value); err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(buf)
+	if s.Indent > 0 {
+		encoder.SetIndent(s.Indent)
+	}
+
+	if err = encoder.Encode(node); err != nil {
+		return nil, err
+	}
+
+	if err = encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s Structured) yamlNode(v any) (_ *yaml.Node, err error) {
+	node := &yaml.Node{}
+	switch t := v.(type) {
+	case nil:
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!null", "null"
+	case string:
+		node.SetString(t)
+	case json.Number:
+		node.Kind, node.Value = yaml.ScalarNode, t.String()
+		if _, err = strconv.ParseInt(t.String(), 10, 64); err == nil {
+			node.Tag = "!!int"
+		} else {
+			node.Tag = "!!float"
+		}
+	case []any:
+		node.Kind, node.Tag = yaml.SequenceNode, "!!seq"
+		if s.Compact {
+			node.Style = yaml.FlowStyle
+		}
+
+		for _, item := range t {
+			var child *yaml.Node
+			if child, err = s.yamlNode(item); err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+	case *orderedMap:
+		node.Kind, node.Tag = yaml.MappingNode, "!!map"
+		if s.Compact {
+			node.Style = yaml.FlowStyle
+		}
+
+		for _, key := range t.orderedKeys(s.Sorted, s.Canonical) {
+			keyNode := &yaml.Node{}
+			keyNode.SetString(key)
+
+			var child *yaml.Node
+			if child, err = s.yamlNode(t.values[key]); err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, keyNode, child)
+		}
+	default:
+		if err = node.Encode(v); err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
+func (s Structured) marshalTOML() (_ []byte, err error) {
+	root, ok := plainValue(s.value).(map[string]any)
+	if !ok {
+		return nil, errors.New("toml documents must be a table")
+	}
+
+	if err = checkTOMLValue(root); err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	encoder := toml.NewEncoder(buf)
+	if s.Compact {
+		encoder.Indent = ""
+	} else {
+		encoder.Indent = strings.Repeat(" ", s.Indent)
+	}
+
+	if err = encoder.Encode(root); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// TOML cannot represent null values, which the encoder would silently drop.
+func checkTOMLValue(v any) error {
+	switch t := v.(type) {
+	case nil:
+		return errors.New("toml cannot represent null values")
+	case map[string]any:
+		for _, val := range t {
+			if err := checkTOMLValue(val); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, val := range t {
+			if err := checkTOMLValue(val); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type StructuredFormat uint8
+
+func (f StructuredFormat) String() string {
+	switch f {
+	case JSONFormat:
+		return "json"
+	case YAMLFormat:
+		return "yaml"
+	case TOMLFormat:
+		return "toml"
+	default:
+		return "unknown"
+	}
+}
+
+//===========================================================================
+// Ordered documents
+//===========================================================================
+
+// orderedMap is an object that preserves the order its keys were parsed in.
+type orderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+func newOrderedMap() *orderedMap {
+	return &orderedMap{values: make(map[string]any)}
+}
+
+// Set the value of the key; duplicate keys keep their original position.
+func (m *orderedMap) set(key string, val any) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = val
+}
+
+func (m *orderedMap) orderedKeys(sorted, canonical bool) []string {
+	if !sorted && !canonical {
+		return m.keys
+	}
+
+	keys := append([]string(nil), m.keys...)
+	if canonical {
+		// RFC 8785 sorts keys by their UTF-16 code units
+		sort.Slice(keys, func(i, j int) bool {
+			a, b := utf16.Encode([]rune(keys[i])), utf16.Encode([]rune(keys[j]))
+			for k := 0; k < len(a) && k < len(b); k++ {
+				if a[k] != b[k] {
+					return a[k] < b[k]
+				}
+			}
+			return len(a) < len(b)
+		})
+	} else {
+		sort.Strings(keys)
+	}
+	return keys
+}
+
+// Convert ordered maps into map[string]any and json.Number into int64, uint64 (for
+// integers that overflow an int64), or float64.
+func plainValue(v any) any {
+	switch t := v.(type) {
+	case *orderedMap:
+		out := make(map[string]any, len(t.keys))
+		for key, val := range t.values {
+			out[key] = plainValue(val)
+		}
+		return out
+	case []any:
+		out := make([]any, len(t))
+		for i, val := range t {
+			out[i] = plainValue(val)
+		}
+		return out
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+
+		if u, err := strconv.ParseUint(t.String(), 10, 64); err == nil {
+			return u
+		}
+		f, _ := t.Float64()
+		return f
+	default:
+		return v
+	}
+}
+
+// Parse a single JSON document preserving the key order of objects and the literal
+// representation of numbers.
+func parseOrderedJSON(data []byte) (v any, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if v, err = readJSONValue(decoder); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	if _, err = decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("unexpected data after the json document")
+	}
+	return v, nil
+}
+
+func readJSONValue(decoder *json.Decoder) (_ any, err error) {
+	var token json.Token
+	if token, err = decoder.Token(); err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		obj := newOrderedMap()
+		for decoder.More() {
+			if token, err = decoder.Token(); err != nil {
+				return nil, err
+			}
+
+			var val any
+			if val, err = readJSONValue(decoder); err != nil {
+				return nil, err
+			}
+			obj.set(token.(string), val)
+		}
+
+		if _, err = decoder.Token(); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	case json.Delim('['):
+		arr := make([]any, 0)
+		for decoder.More() {
+			var val any
+			if val, err = readJSONValue(decoder); err != nil {
+				return nil, err
+			}
+			arr = append(arr, val)
+		}
+
+		if _, err = decoder.Token(); err != nil {
+			return nil, err
+		}
+		return arr, nil
+	default:
+		return token, nil
+	}
+}
+
+// Parse the first YAML document preserving the key order of mappings.
+func parseOrderedYAML(data []byte) (_ any, err error) {
+	var node yaml.Node
+	if err = yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	return yamlValue(&node)
+}
+
+func yamlValue(node *yaml.Node) (_ any, err error) {
+	switch node.Kind {
+	case 0:
+		return nil, nil
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+		return yamlValue(node.Content[0])
+	case yaml.AliasNode:
+		return yamlValue(node.Alias)
+	case yaml.ScalarNode:
+		if node.ShortTag() == "!!str" {
+			return node.Value, nil
+		}
+
+		var val any
+		if err = node.Decode(&val); err != nil {
+			return nil, err
+		}
+		return val, nil
+	case yaml.SequenceNode:
+		arr := make([]any, 0, len(node.Content))
+		for _, child := range node.Content {
+			var val any
+			if val, err = yamlValue(child); err != nil {
+				return nil, err
+			}
+			arr = append(arr, val)
+		}
+		return arr, nil
+	case yaml.MappingNode:
+		obj := newOrderedMap()
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, child := node.Content[i], node.Content[i+1]
+
+			var val any
+			if val, err = yamlValue(child); err != nil {
+				return nil, err
+			}
+
+			if key.ShortTag() == "!!merge" {
+				if err = mergeYAML(obj, val); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			if key.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("line %d: mapping keys must be scalars", key.Line)
+			}
+			obj.set(key.Value, val)
+		}
+		return obj, nil
+	default:
+		return nil, fmt.Errorf("line %d: unhandled yaml node", node.Line)
+	}
+}
+
+// Merge keys (<<) add the keys of one or more mappings that are not already defined.
+func mergeYAML(obj *orderedMap, val any) error {
+	switch t := val.(type) {
+	case *orderedMap:
+		for _, key := range t.keys {
+			if _, ok := obj.values[key]; !ok {
+				obj.set(key, t.values[key])
+			}
+		}
+	case []any:
+		for _, item := range t {
+			if err := mergeYAML(obj, item); err != nil {
+				return err
+			}
+		}
+	default:
+		return errors.New("merge keys must refer to mappings")
+	}
+	return nil
+}
+
+// Parse a TOML document, using the metadata to preserve the key order of tables.
+func parseOrderedTOML(data string) (_ any, err error) {
+	var (
+		raw  map[string]any
+		meta toml.MetaData
+	)
+
+	if meta, err = toml.Decode(data, &raw); err != nil {
+		return nil, err
+	}
+
+	// Record the order the keys appear in for each table path; arrays of tables share
+	// the same path and therefore the same order.
+	order := make(map[string][]string)
+	seen := make(map[string]struct{})
+	for _, key := range meta.Keys() {
+		parent := strings.Join(key[:len(key)-1], "\x00")
+		if _, ok := seen[parent+"\x01"+key[len(key)-1]]; !ok {
+			seen[parent+"\x01"+key[len(key)-1]] = struct{}{}
+			order[parent] = append(order[parent], key[len(key)-1])
+		}
+	}
+	return tomlValue(raw, nil, order), nil
+}
+
+func tomlValue(v any, path []string, order map[string][]string) any {
+	switch t := v.(type) {
+	case map[string]any:
+		obj := newOrderedMap()
+		for _, key := range order[strings.Join(path, "\x00")] {
+			if val, ok := t[key]; ok {
+				obj.set(key, tomlValue(val, append(path[:len(path):len(path)], key), order))
+			}
+		}
+
+		remaining := make([]string, 0)
+		for key := range t {
+			if _, ok := obj.values[key]; !ok {
+				remaining = append(remaining, key)
+			}
+		}
+
+		sort.Strings(remaining)
+		for _, key := range remaining {
+			obj.set(key, tomlValue(t[key], append(path[:len(path):len(path)], key), order))
+		}
+		return obj
+	case []map[string]any:
+		arr := make([]any, 0, len(t))
+		for _, item := range t {
+			arr = append(arr, tomlValue(item, path, order))
+		}
+		return arr
+	case []any:
+		arr := make([]any, 0, len(t))
+		for _, item := range t {
+			arr = append(arr, tomlValue(item, path, order))
+		}
+		return arr
+	default:
+		return v
+	}
+}
+
+//===========================================================================
+// JSON Serialization
+//===========================================================================
+
+// jsonWriter serializes ordered documents as compact JSON. Strings are escaped as
+// described in RFC 8785 (which is also valid for non-canonical output) and in
+// canonical mode numbers are formatted as IEEE 754 doubles using the ECMAScript rules.
+type jsonWriter struct {
+	sorted    bool
+	canonical bool
+}
+
+func (w jsonWriter) write(buf *bytes.Buffer, v any) (err error) {
+	switch t := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(t))
+	case string:
+		writeJSONString(buf, t)
+	case json.Number:
+		if !w.canonical {
+			buf.WriteString(t.String())
+			return nil
+		}
+
+		var f float64
+		if f, err = strconv.ParseFloat(t.String(), 64); err != nil {
+			return err
+		}
+		return w.writeFloat(buf, f)
+	case int:
+		return w.writeInt(buf, int64(t))
+	case int64:
+		return w.writeInt(buf, t)
+	case uint64:
+		if w.canonical {
+			return w.writeFloat(buf, float64(t))
+		}
+		buf.WriteString(strconv.FormatUint(t, 10))
+	case float64:
+		return w.writeFloat(buf, t)
+	case time.Time:
+		writeJSONString(buf, t.Format(time.RFC3339Nano))
+	case []byte:
+		writeJSONString(buf, base64.StdEncoding.EncodeToString(t))
+	case encoding.TextMarshaler:
+		var text []byte
+		if text, err = t.MarshalText(); err != nil {
+			return err
+		}
+		writeJSONString(buf, string(text))
+	case []any:
+		buf.WriteByte('[')
+		for i, item := range t {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			if err = w.write(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case *orderedMap:
+		buf.WriteByte('{')
+		for i, key := range t.orderedKeys(w.sorted, w.canonical) {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			writeJSONString(buf, key)
+			buf.WriteByte(':')
+			if err = w.write(buf, t.values[key]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("cannot represent %T as json", v)
+	}
+	return nil
+}
+
+func (w jsonWriter) writeInt(buf *bytes.Buffer, i int64) error {
+	if w.canonical {
+		return w.writeFloat(buf, float64(i))
+	}
+	buf.WriteString(strconv.FormatInt(i, 10))
+	return nil
+}
+
+// Numbers are formatted using the shortest representation that round trips, in the
+// same format as ECMAScript's Number.prototype.toString (as does encoding/json).
+func (w jsonWriter) writeFloat(buf *bytes.Buffer, f float64) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("cannot represent %v as json", f)
+	}
+
+	// RFC 8785 serializes negative zero as 0
+	if w.canonical && f == 0 {
+		f = 0
+	}
+
+	data, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	buf.Write(data)
+	return nil
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	const hex = "0123456789abcdef"
+	buf.WriteByte('"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if r == utf8.RuneError && size == 1 {
+				buf.WriteString(`\ufffd`)
+			} else {
+				buf.WriteString(s[i : i+size])
+			}
+			i += size
+			continue
+		}
+
+		switch c {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if c < 0x20 {
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hex[c>>4])
+				buf.WriteByte(hex[c&0xf])
+			} else {
+				buf.WriteByte(c)
+			}
+		}
+		i++
+	}
+	buf.WriteByte('"')
+}
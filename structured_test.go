@@ -0,0 +1,185 @@
+package binutil_test
+
+import (
+	"testing"
+
+	"github.com/bbengfort/binutil"
+	"github.com/stretchr/testify/require"
+)
+
+const yamlFixture = `name: binutil
+version: 3
+defaults: &defaults
+  retries: 2
+  timeout: 1.5
+service:
+  <<: *defaults
+  timeout: 3
+  hosts:
+    - alpha
+    - beta
+enabled: true
+notes: null
+`
+
+func TestStructuredConversion(t *testing.T) {
+	pipe, err := binutil.New("yaml", "json:compact")
+	require.NoError(t, err, "could not create yaml to json pipeline")
+
+	out, err := pipe.Str2Str(yamlFixture)
+	require.NoError(t, err, "could not convert yaml to json")
+	require.Equal(t, `{"name":"binutil","version":3,"defaults":{"retries":2,"timeout":1.5},"service":{"retries":2,"timeout":3,"hosts":["alpha","beta"]},"enabled":true,"notes":null}`, out, "expected key order to be preserved and anchors to be resolved")
+
+	pipe, err = binutil.New("json", "toml")
+	require.NoError(t, err, "could not create json to toml pipeline")
+
+	out, err = pipe.Str2Str(`{"title": "x", "owner": {"name": "a"}, "ports": [80, 443]}`)
+	require.NoError(t, err, "could not convert json to toml")
+	require.Equal(t, "ports = [80, 443]\ntitle = \"x\"\n\n[owner]\n  name = \"a\"\n", out)
+
+	pipe, err = binutil.New("toml", "yaml")
+	require.NoError(t, err, "could not create toml to yaml pipeline")
+
+	out, err = pipe.Str2Str("zeta = 1\nalpha = \"b\"\n\n[table]\nkey = [1.5, 2.5]\n")
+	require.NoError(t, err, "could not convert toml to yaml")
+	require.Equal(t, "zeta: 1\nalpha: b\ntable:\n  key:\n    - 1.5\n    - 2.5\n", out, "expected toml key order to be preserved")
+
+	// TOML cannot represent null or non-table documents
+	pipe, err = binutil.New("json", "toml")
+	require.NoError(t, err, "could not create json to toml pipeline")
+
+	_, err = pipe.Str2Str(`{"a": null}`)
+	require.Error(t, err, "expected null to be rejected by toml")
+
+	_, err = pipe.Str2Str(`[1, 2]`)
+	require.Error(t, err, "expected array document to be rejected by toml")
+}
+
+func TestStructuredValidation(t *testing.T) {
+	for _, name := range []string{"json", "yaml", "toml"} {
+		pipe, err := binutil.New(name)
+		require.NoError(t, err, "could not create %s pipeline", name)
+
+		_, err = pipe.Str2Str("{\"a\": [1, 2}")
+		require.Error(t, err, "expected invalid document to be rejected by %s", name)
+	}
+
+	pipe, err := binutil.New("json")
+	require.NoError(t, err, "could not create json pipeline")
+
+	_, err = pipe.Str2Str(`{"a": 1} {"b": 2}`)
+	require.Error(t, err, "expected trailing data to be rejected")
+
+	_, err = pipe.Str2Str("")
+	require.Error(t, err, "expected empty input to be rejected")
+}
+
+func TestStructuredFormatting(t *testing.T) {
+	testCases := []struct {
+		spec     string
+		in       string
+		expected string
+	}{
+		{"json", `{"b": 1, "a": [1.50, 2]}`, "{\n  \"b\": 1,\n  \"a\": [\n    1.50,\n    2\n  ]\n}"},
+		{"json:indent=4", `{"b": 1}`, "{\n    \"b\": 1\n}"},
+		{"json:compact", `{"b": 1, "a": [1.50, 2]}`, `{"b":1,"a":[1.50,2]}`},
+		{"json:compact,sorted", `{"b": 1, "a": {"d": 1, "c": 2}}`, `{"a":{"c":2,"d":1},"b":1}`},
+		{"json:canonical", `{"b": 1, "a": [1.50, 2, -0.0]}`, `{"a":[1.5,2,0],"b":1}`},
+		{"yaml", `{"b": 1, "a": [1.50, "x"]}`, "b: 1\na:\n  - 1.5\n  - x\n"},
+		{"yaml:compact", `{"b": 1, "a": [1.50, "x"]}`, "{b: 1, a: [1.5, x]}\n"},
+		{"yaml:sorted", `{"b": 1, "a": "2"}`, "a: \"2\"\nb: 1\n"},
+	}
+
+	for _, tc := range testCases {
+		pipe, err := binutil.New(tc.spec)
+		require.NoError(t, err, "could not create pipeline for %s", tc.spec)
+
+		out, err := pipe.Str2Str(tc.in)
+		require.NoError(t, err, "could not format %s", tc.spec)
+		require.Equal(t, tc.expected, out, "unexpected output for %s", tc.spec)
+	}
+}
+
+func TestStructuredBinary(t *testing.T) {
+	// The binary representation is compact JSON unless the pretty flag is set
+	pipe, err := binutil.New("yaml")
+	require.NoError(t, err, "could not create yaml pipeline")
+
+	data, err := pipe.Str2Bin("a: 1\nb: [x]\n")
+	require.NoError(t, err, "could not encode yaml as binary")
+	require.Equal(t, `{"a":1,"b":["x"]}`, string(data))
+
+	// Binary YAML or JSON is accepted by the yaml step
+	out, err := pipe.Bin2Str([]byte("a: 1\n"))
+	require.NoError(t, err, "could not decode yaml bytes")
+	require.Equal(t, "a: 1\n", out)
+
+	out, err = pipe.Bin2Str([]byte(`{"a": 1}`))
+	require.NoError(t, err, "could not decode json bytes")
+	require.Equal(t, "a: 1\n", out)
+
+	pipe, err = binutil.New("yaml:pretty")
+	require.NoError(t, err, "could not create yaml pipeline")
+
+	data, err = pipe.Str2Bin("a: 1\n")
+	require.NoError(t, err, "could not encode yaml as binary")
+	require.Equal(t, "{\n  \"a\": 1\n}", string(data))
+
+	// The json step only accepts JSON as binary input
+	pipe, err = binutil.New("json")
+	require.NoError(t, err, "could not create json pipeline")
+
+	_, err = pipe.Bin2Bin([]byte("a: 1\n"))
+	require.Error(t, err, "expected yaml to be rejected by the json step")
+}
+
+func TestCanonicalJSON(t *testing.T) {
+	pipe, err := binutil.New("json:canonical")
+	require.NoError(t, err, "could not create canonical json pipeline")
+
+	// Test vectors from RFC 8785 sections 3.2.2 and 3.2.3
+	testCases := []struct {
+		in       string
+		expected string
+	}{
+		{
+			`{
+			  "numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+			  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+			  "literals": [null, true, false]
+			}`,
+			`{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			`{
+			  "\u20ac": "Euro Sign",
+			  "\r": "Carriage Return",
+			  "\ufb33": "Hebrew Letter Dalet With Dagesh",
+			  "1": "One",
+			  "\ud83d\ude00": "Emoji: Grinning Face",
+			  "\u0080": "Control",
+			  "\u00f6": "Latin Small Letter O With Diaeresis"
+			}`,
+			"{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"ö\":\"Latin Small Letter O With Diaeresis\",\"€\":\"Euro Sign\",\"😀\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}",
+		},
+	}
+
+	for i, tc := range testCases {
+		out, err := pipe.Str2Str(tc.in)
+		require.NoError(t, err, "could not canonicalize test case %d", i)
+		require.Equal(t, tc.expected, out, "unexpected canonical json for test case %d", i)
+
+		// The binary representation is identical so it can be hashed
+		data, err := pipe.Str2Bin(tc.in)
+		require.NoError(t, err, "could not canonicalize test case %d", i)
+		require.Equal(t, tc.expected, string(data), "unexpected canonical json for test case %d", i)
+	}
+
+	// YAML can be canonicalized by chaining the json step
+	pipe, err = binutil.New("yaml", "json:canonical")
+	require.NoError(t, err, "could not create yaml to canonical json pipeline")
+
+	out, err := pipe.Str2Bin("b: 1.0\na: [true]\n")
+	require.NoError(t, err, "could not canonicalize yaml")
+	require.Equal(t, `{"a":[true],"b":1}`, string(out))
+}
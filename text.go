@@ -21,6 +21,11 @@ func init() {
 	RegisterDecoder(UTF8Decoder, func() Decoder { return NewText(UTF8Encoding) }, "utf8")
 	RegisterDecoder(ASCIIDecoder, func() Decoder { return NewText(ASCIIEncoding) })
 	RegisterDecoder(Latin1Decoder, func() Decoder { return NewText(Latin1Encoding) }, "latin-1")
+
+	DescribeDecoder(TextDecoder, Info{Description: "UTF-8 text", Category: TextCategory, Capabilities: Bidirectional})
+	DescribeDecoder(UTF8Decoder, Info{Description: "UTF-8 text", Category: TextCategory, Capabilities: Bidirectional})
+	DescribeDecoder(ASCIIDecoder, Info{Description: "ASCII text", Category: TextCategory, Capabilities: Bidirectional | Lossy})
+	DescribeDecoder(Latin1Decoder, Info{Description: "ISO-8859-1 text", Category: TextCategory, Capabilities: Bidirectional | Lossy})
 }
 
 const (
@@ -0,0 +1,67 @@
+package binutil
+
+import (
+	"context"
+	"encoding/hex"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// PreviewSize is the maximum number of bytes of intermediate output in a trace preview.
+const PreviewSize = 24
+
+// StepTrace records the conversion performed by a single step of a pipeline.
+type StepTrace struct {
+	Step     int
+	Name     string
+	Input    int           // the size of the step input in bytes
+	Output   int           // the size of the step output in bytes
+	Duration time.Duration // the time taken to decode and encode the data
+	Preview  string        // a truncated rendering of the step output
+	Err      error         // the error if the step failed
+}
+
+// Trace converts the input string like Str2Str and records the decoder name, input and
+// output sizes, duration, and a preview of the output of every step so that the step
+// of a long pipeline that produces unexpected output can be found. Previews of UTF-8
+// output are quoted strings and previews of binary output are hex; both are truncated
+// to PreviewSize bytes. If a step fails, the trace includes the failed step with its
+// error and the error is returned along with the trace.
+func (p *Pipeline) Trace(in string) (out string, steps []StepTrace, err error) {
+	return p.TraceContext(context.Background(), in)
+}
+
+// TraceContext is Trace that stops between steps if the context is cancelled.
+func (p *Pipeline) TraceContext(ctx context.Context, in string) (out string, steps []StepTrace, err error) {
+	steps = make([]StepTrace, 0, len(p.steps))
+	if _, out, err = p.run(ctx, nil, in, true, true, func(step StepTrace) { steps = append(steps, step) }); err != nil {
+		return "", steps, err
+	}
+	return out, steps, nil
+}
+
+func preview(data []byte) string {
+	truncated := len(data) > PreviewSize
+	if truncated {
+		data = data[:PreviewSize]
+	}
+
+	// Drop a rune that was cut off by the truncation
+	text := data
+	for i := 0; truncated && i < utf8.UTFMax-1 && len(text) > 0 && !utf8.Valid(text); i++ {
+		text = text[:len(text)-1]
+	}
+
+	var out string
+	if printable(text) {
+		out = strconv.Quote(string(text))
+	} else {
+		out = hex.EncodeToString(data)
+	}
+
+	if truncated {
+		out += "..."
+	}
+	return out
+}
@@ -0,0 +1,60 @@
+package binutil_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bbengfort/binutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipelineTrace(t *testing.T) {
+	pipe, err := binutil.New("ulid", "hex", &binutil.Hex{}, "b64")
+	require.NoError(t, err)
+
+	out, steps, err := pipe.Trace("01H3W3MX9A4AFNW55R0MNMQR6Y")
+	require.NoError(t, err)
+	require.Equal(t, "AYj4OnUqIp9eFLgFK0vg3g==", out)
+	require.Len(t, steps, 4)
+
+	expected := []struct {
+		name    string
+		input   int
+		output  int
+		preview string
+	}{
+		{"ulid", 26, 16, "0188f83a752a229f5e14b8052b4be0de"},
+		{"hex", 16, 16, "0188f83a752a229f5e14b8052b4be0de"},
+		{"*binutil.Hex", 16, 16, "0188f83a752a229f5e14b8052b4be0de"},
+		{"base64", 16, 24, `"AYj4OnUqIp9eFLgFK0vg3g=="`},
+	}
+
+	for i, step := range steps {
+		require.Equal(t, i, step.Step)
+		require.Equal(t, expected[i].name, step.Name)
+		require.Equal(t, expected[i].input, step.Input)
+		require.Equal(t, expected[i].output, step.Output)
+		require.Equal(t, expected[i].preview, step.Preview)
+		require.NoError(t, step.Err)
+	}
+
+	// Previews are truncated
+	pipe, err = binutil.New("text", "hex")
+	require.NoError(t, err)
+
+	_, steps, err = pipe.Trace(strings.Repeat("é", 20))
+	require.NoError(t, err)
+	require.Equal(t, `"`+strings.Repeat("é", 12)+`"...`, steps[0].Preview)
+	require.Equal(t, `"`+strings.Repeat("c3a9", 6)+`"...`, steps[1].Preview)
+
+	// A failed step is included in the trace
+	pipe, err = binutil.New("b64", "ulid", "hex")
+	require.NoError(t, err)
+
+	_, steps, err = pipe.Trace("3q2+7w==")
+	require.Error(t, err)
+	require.Len(t, steps, 2)
+	require.NoError(t, steps[0].Err)
+	require.Equal(t, 4, steps[1].Input)
+	require.Equal(t, err, steps[1].Err)
+}
@@ -4,6 +4,7 @@ import "github.com/oklog/ulid/v2"
 
 func init() {
 	RegisterDecoder(ULIDDecoder, func() Decoder { return &ULID{} })
+	DescribeDecoder(ULIDDecoder, Info{Description: "universally unique lexicographically sortable identifiers", Category: IdentifierCategory, Length: 16, Capabilities: Bidirectional})
 }
 
 const ULIDDecoder = "ulid"
@@ -13,8 +14,10 @@ type ULID struct {
 }
 
 var (
-	_ Encoder = &ULID{}
-	_ Decoder = &ULID{}
+	_ Encoder       = &ULID{}
+	_ Decoder       = &ULID{}
+	_ AppendEncoder = &ULID{}
+	_ AppendDecoder = &ULID{}
 )
 
 func (u ULID) DecodeBinary(in []byte) (_ Encoder, err error) {
@@ -38,3 +41,26 @@ func (u ULID) EncodeBinary() ([]byte, error) {
 func (u ULID) EncodeString() (string, error) {
 	return u.ULID.String(), nil
 }
+
+// AppendEncode appends the string representation of the 16 byte ULID in src to dst.
+func (u ULID) AppendEncode(dst, src []byte) (_ []byte, err error) {
+	if err = u.ULID.UnmarshalBinary(src); err != nil {
+		return dst, err
+	}
+
+	var tail []byte
+	size := len(dst)
+	dst, tail = grow(dst, ulid.EncodedSize)
+	if err = u.ULID.MarshalTextTo(tail); err != nil {
+		return dst[:size], err
+	}
+	return dst, nil
+}
+
+// AppendDecode appends the 16 bytes of the ULID string in src to dst.
+func (u ULID) AppendDecode(dst, src []byte) (_ []byte, err error) {
+	if u.ULID, err = ulid.ParseStrict(string(src)); err != nil {
+		return dst, err
+	}
+	return append(dst, u.ULID[:]...), nil
+}
@@ -1,9 +1,14 @@
 package binutil
 
-import "github.com/google/uuid"
+import (
+	"encoding/hex"
+
+	"github.com/google/uuid"
+)
 
 func init() {
 	RegisterDecoder(UUIDDecoder, func() Decoder { return &UUID{} }, "uuid4", "uuid5")
+	DescribeDecoder(UUIDDecoder, Info{Description: "universally unique identifiers (RFC 4122)", Category: IdentifierCategory, Length: 16, Capabilities: Bidirectional})
 }
 
 const UUIDDecoder = "uuid"
@@ -13,8 +18,10 @@ type UUID struct {
 }
 
 var (
-	_ Encoder = &UUID{}
-	_ Decoder = &UUID{}
+	_ Encoder       = &UUID{}
+	_ Decoder       = &UUID{}
+	_ AppendEncoder = &UUID{}
+	_ AppendDecoder = &UUID{}
 )
 
 func (u UUID) DecodeBinary(in []byte) (_ Encoder, err error) {
@@ -38,3 +45,31 @@ func (u UUID) EncodeBinary() ([]byte, error) {
 func (u UUID) EncodeString() (string, error) {
 	return u.UUID.String(), nil
 }
+
+// AppendEncode appends the string representation of the 16 byte UUID in src to dst.
+func (u UUID) AppendEncode(dst, src []byte) (_ []byte, err error) {
+	if err = u.UUID.UnmarshalBinary(src); err != nil {
+		return dst, err
+	}
+
+	var tail []byte
+	dst, tail = grow(dst, 36)
+	hex.Encode(tail[0:8], u.UUID[0:4])
+	tail[8] = '-'
+	hex.Encode(tail[9:13], u.UUID[4:6])
+	tail[13] = '-'
+	hex.Encode(tail[14:18], u.UUID[6:8])
+	tail[18] = '-'
+	hex.Encode(tail[19:23], u.UUID[8:10])
+	tail[23] = '-'
+	hex.Encode(tail[24:], u.UUID[10:])
+	return dst, nil
+}
+
+// AppendDecode appends the 16 bytes of the UUID string in src to dst.
+func (u UUID) AppendDecode(dst, src []byte) (_ []byte, err error) {
+	if u.UUID, err = uuid.ParseBytes(src); err != nil {
+		return dst, err
+	}
+	return append(dst, u.UUID[:]...), nil
+}
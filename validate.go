@@ -0,0 +1,74 @@
+package binutil
+
+import "fmt"
+
+// Diagnostic describes an incompatibility between a step of a pipeline and its
+// neighbours. Diagnostics wrap ErrInvalidPipeline.
+type Diagnostic struct {
+	Step    int
+	Name    string
+	Message string
+}
+
+// Validate statically checks that consecutive steps of the pipeline are compatible
+// using the information the decoders were described with and returns the problems that
+// would make every conversion fail: intermediate steps must be able to encode binary
+// data for the next step and decode binary data from the previous step (e.g. a one-way
+// digest cannot be followed by a step that expects it to be decoded), and adjacent
+// steps with fixed lengths must agree on the length of the data (e.g. a 20 byte KSUID
+// into a 16 byte UUID). Only constraints that are known before the pipeline is run are
+// checked, so steps that were not described (e.g. Decoder instances) and the length of
+// variable length data (e.g. hex into ulid) are not validated. Because the direction of
+// the conversion is not known, the first and last steps are not checked for string
+// support; use Registry.Check for that.
+func (p *Pipeline) Validate() (diags []Diagnostic) {
+	last := len(p.steps) - 1
+	for i := range p.steps {
+		info := p.info(i)
+		if info == nil {
+			continue
+		}
+
+		if i > 0 && !info.Capabilities.Has(DecodesBinary) {
+			diags = append(diags, p.diagnostic(i, "cannot decode the binary data produced by step %d (%s)", i-1, p.name(i-1)))
+		}
+
+		if i < last && !info.Capabilities.Has(EncodesBinary) {
+			diags = append(diags, p.diagnostic(i, "cannot encode binary data for step %d (%s)", i+1, p.name(i+1)))
+		}
+
+		if i > 0 && info.Length > 0 {
+			if prev := p.info(i - 1); prev != nil && prev.Length > 0 && prev.Length != info.Length {
+				diags = append(diags, p.diagnostic(i, "expects %d bytes but step %d (%s) produces %d bytes", info.Length, i-1, prev.Name, prev.Length))
+			}
+		}
+	}
+	return diags
+}
+
+func (p *Pipeline) info(i int) *Info {
+	if i < len(p.infos) {
+		return p.infos[i]
+	}
+	return nil
+}
+
+func (p *Pipeline) name(i int) string {
+	if info := p.info(i); info != nil {
+		return info.Name
+	}
+	return fmt.Sprintf("%T", p.steps[i])
+}
+
+func (p *Pipeline) diagnostic(i int, format string, args ...any) Diagnostic {
+	return Diagnostic{Step: i, Name: p.name(i), Message: fmt.Sprintf(format, args...)}
+}
+
+// Error returns the diagnostic with its step position, e.g. step 1 (uuid): message.
+func (d Diagnostic) Error() string {
+	return fmt.Sprintf("step %d (%s): %s", d.Step, d.Name, d.Message)
+}
+
+func (d Diagnostic) Unwrap() error {
+	return ErrInvalidPipeline
+}
@@ -0,0 +1,65 @@
+package binutil_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bbengfort/binutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipelineValidate(t *testing.T) {
+	registry := binutil.DefaultRegistry.Clone()
+	registry.Register("ksuid", func() binutil.Decoder { return &binutil.Hex{} })
+	registry.Describe("ksuid", binutil.Info{Category: binutil.IdentifierCategory, Length: 20, Capabilities: binutil.Bidirectional})
+	registry.Register("digest", func() binutil.Decoder { return &binutil.Hex{} })
+	registry.Describe("digest", binutil.Info{Category: binutil.CryptoCategory, Capabilities: binutil.DecodesBinary | binutil.DecodesString | binutil.EncodesBinary})
+	registry.Register("sink", func() binutil.Decoder { return &binutil.Hex{} })
+	registry.Describe("sink", binutil.Info{Capabilities: binutil.DecodesString | binutil.EncodesString})
+
+	testCases := []struct {
+		steps []any
+		err   string
+	}{
+		{[]any{"hex", "b64"}, ""},
+		{[]any{"ulid", "uuid"}, ""},
+		{[]any{"hex", "ksuid", "b64"}, ""},
+		{[]any{"digest", "hex"}, ""},
+		{[]any{"ksuid", "uuid"}, "step 1 (uuid): expects 16 bytes but step 0 (ksuid) produces 20 bytes"},
+		{[]any{"uuid", "ksuid"}, "step 1 (ksuid): expects 20 bytes but step 0 (uuid) produces 16 bytes"},
+		{[]any{"hex", "sink"}, "step 1 (sink): cannot decode the binary data produced by step 0 (hex)"},
+		{[]any{"sink", "hex"}, "step 0 (sink): cannot encode binary data for step 1 (hex)"},
+	}
+
+	for i, tc := range testCases {
+		steps := append(tc.steps, binutil.WithRegistry(registry))
+		pipe, err := binutil.New(steps...)
+		if tc.err == "" {
+			require.NoError(t, err, "test case %d failed", i)
+			require.NotNil(t, pipe, "test case %d failed", i)
+			continue
+		}
+
+		require.EqualError(t, err, tc.err, "test case %d failed", i)
+		require.ErrorIs(t, err, binutil.ErrInvalidPipeline, "test case %d failed", i)
+
+		var diag binutil.Diagnostic
+		require.True(t, errors.As(err, &diag), "test case %d failed", i)
+		require.Equal(t, tc.err, diag.Error(), "test case %d failed", i)
+	}
+}
+
+func TestPipelineValidateUnknown(t *testing.T) {
+	// Constraints that are not known before the pipeline is run are not reported
+	for _, steps := range [][]any{
+		{"hex", &binutil.Hex{}, "ascii"},
+		{"hex", "ulid"},
+		{"b64", "uuid"},
+		{"uuid", "b64"},
+		{"ulid", "uuid"},
+	} {
+		pipe, err := binutil.New(steps...)
+		require.NoError(t, err)
+		require.Empty(t, pipe.Validate(), "expected no diagnostics for %v", steps)
+	}
+}